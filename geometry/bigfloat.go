@@ -40,43 +40,9 @@ func IsPointOn(l Line, pt Point) bool {
 		return false
 	}
 
-	// vertical line
-	if x1.Cmp(x2) == 0 {
-		return x1.Cmp(pt[0]) == 0 &&
-			y1.Cmp(pt[1]) <= 0 && pt[1].Cmp(y2) <= 0
-	}
-
-	// horizontal line
-	if y1.Cmp(y2) == 0 {
-		return y1.Cmp(pt[1]) == 0 &&
-			x1.Cmp(pt[0]) <= 0 && pt[0].Cmp(x2) <= 0
-	}
-
-	// Match the gradients
-	side1 := new(big.Float).Mul(
-		new(big.Float).Sub(
-			x1,
-			pt[0],
-		),
-		new(big.Float).Sub(
-			y1,
-			pt[1],
-		),
-	)
-	side2 := new(big.Float).Mul(
-		new(big.Float).Sub(
-			pt[0],
-			x2,
-		),
-		new(big.Float).Sub(
-			pt[1],
-			y2,
-		),
-	)
-	return side1.Cmp(side2) == 0
-
-	//	return (x1-pt[0])*(y1-pt[1]) == (pt[0]-x2)*(pt[1]-y2)
-
+	// Within the extent of the line, so the point is on the line
+	// if it is colinear with the end points.
+	return Orientation(l[0], l[1], pt) == 0
 }
 
 // ArePointsEqual return if the two points are equal
@@ -86,23 +52,31 @@ func ArePointsEqual(a, b Point) bool {
 	//	return a[0] == b[0] && a[1] == b[1]
 }
 
-// TriArea reaturns twice the area of the oriented triangle (a,b,c), i.e.
-// the area is positive if the triangle is oriented counterclockwise.
-func triArea(a, b, c Point) *big.Float {
-
-	//return (b[0]-a[0])*(c[1]-a[1]) - (b[1]-a[1])*(c[0]-a[0])
+// rat returns the exact value of f. The predicates are computed with
+// big.Rat, as the precision of a big.Float is not enough to hold the
+// intermediate results exactly.
+func rat(f *big.Float) *big.Rat {
+	r, _ := f.Rat(nil)
+	return r
+}
 
-	ba0 := new(big.Float).Sub(b[0], a[0])
-	ca0 := new(big.Float).Sub(c[0], a[0])
-	ba1 := new(big.Float).Sub(b[1], a[1])
-	ca1 := new(big.Float).Sub(c[1], a[1])
-	mulba0ca1 := new(big.Float).Mul(ba0, ca1)
-	mulba1ca0 := new(big.Float).Mul(ba1, ca0)
-	return new(big.Float).Sub(mulba0ca1, mulba1ca0)
+// triArea reaturns twice the area of the oriented triangle (a,b,c), i.e.
+// the area is positive if the triangle is oriented counterclockwise.
+func triArea(a, b, c Point) *big.Rat {
+	ax, ay := rat(a[0]), rat(a[1])
+	bax := new(big.Rat).Sub(rat(b[0]), ax)
+	bay := new(big.Rat).Sub(rat(b[1]), ay)
+	cax := new(big.Rat).Sub(rat(c[0]), ax)
+	cay := new(big.Rat).Sub(rat(c[1]), ay)
+	return new(big.Rat).Sub(
+		new(big.Rat).Mul(bax, cay),
+		new(big.Rat).Mul(bay, cax),
+	)
 }
 
 // TriArea reaturns twice the area of the oriented triangle (a,b,c), i.e.
 // the area is positive if the triangle is oriented counterclockwise.
+// The sign of the result is exact.
 func TriArea(a, b, c Point) float64 {
 	result, _ := triArea(a, b, c).Float64()
 	return result
@@ -111,63 +85,47 @@ func TriArea(a, b, c Point) float64 {
 // InCircle indicates weather the point d is inside the circle defined by the points
 // a,b,c. See Guibas and Stolf (1985) p.107
 func InCircle(a, b, c, d Point) bool {
+	return inCircle(a, b, c, d) > 0
+}
+
+// inCircle returns the sign of the InCircle determinant.
+func inCircle(a, b, c, d Point) int {
+	dx, dy := rat(d[0]), rat(d[1])
+	diff := func(p Point) (x, y *big.Rat) {
+		return new(big.Rat).Sub(rat(p[0]), dx), new(big.Rat).Sub(rat(p[1]), dy)
+	}
+	lift := func(x, y *big.Rat) *big.Rat {
+		return new(big.Rat).Add(new(big.Rat).Mul(x, x), new(big.Rat).Mul(y, y))
+	}
+	det2 := func(ax, ay, bx, by *big.Rat) *big.Rat {
+		return new(big.Rat).Sub(new(big.Rat).Mul(ax, by), new(big.Rat).Mul(ay, bx))
+	}
+	adx, ady := diff(a)
+	bdx, bdy := diff(b)
+	cdx, cdy := diff(c)
+	det := new(big.Rat).Mul(lift(adx, ady), det2(bdx, bdy, cdx, cdy))
+	det.Add(det, new(big.Rat).Mul(lift(bdx, bdy), det2(cdx, cdy, adx, ady)))
+	det.Add(det, new(big.Rat).Mul(lift(cdx, cdy), det2(adx, ady, bdx, bdy)))
+	return det.Sign()
+}
 
-	a2 := new(big.Float).Add(
+func lengthSquared(a Point) *big.Float {
+	return new(big.Float).Add(
 		new(big.Float).Mul(a[0], a[0]),
 		new(big.Float).Mul(a[1], a[1]),
 	)
-	b2 := new(big.Float).Add(
-		new(big.Float).Mul(b[0], b[0]),
-		new(big.Float).Mul(b[1], b[1]),
-	)
-	c2 := new(big.Float).Add(
-		new(big.Float).Mul(c[0], c[0]),
-		new(big.Float).Mul(c[1], c[1]),
-	)
-	d2 := new(big.Float).Add(
-		new(big.Float).Mul(d[0], d[0]),
-		new(big.Float).Mul(d[1], d[1]),
-	)
-	tabcd := new(big.Float).Mul(
-		triArea(b, c, d),
-		a2,
-	)
-	taacd := new(big.Float).Mul(
-		triArea(a, c, d),
-		b2,
-	)
-	taabd := new(big.Float).Mul(
-		triArea(a, b, d),
-		c2,
-	)
-	taabc := new(big.Float).Mul(
-		triArea(a, b, c),
-		d2,
-	)
-	result, _ := new(big.Float).Add(
-		new(big.Float).Sub(
-			tabcd,
-			taacd,
-		),
-		new(big.Float).Sub(
-			taabd,
-			taabc,
-		),
-	).Float64()
-	return result > 0
+}
 
-	/*
-		return (a[0]*a[0]+a[1]*a[1])*TriArea(b, c, d)-
-			(b[0]*b[0]+b[1]*b[1])*TriArea(a, c, d)+
-			(c[0]*c[0]+c[1]*c[1])*TriArea(a, b, d)-
-			(d[0]*d[0]+d[1]*d[1])*TriArea(a, b, c) > 0
-	*/
+// cmpXY compares the x and y coordinates of a and b; each value
+// will be -1, 0, or 1 if a's coordinate is less, equal, or greater.
+func cmpXY(a, b Point) (x, y int) {
+	return a[0].Cmp(b[0]), a[1].Cmp(b[1])
 }
 
 func CrossProduct(a, b Point) float64 {
 	f, _ := new(big.Float).Sub(
-		new(big.Float).Mul(a[0], b[0]),
-		new(big.Float).Mul(a[1], b[1]),
+		new(big.Float).Mul(a[0], b[1]),
+		new(big.Float).Mul(a[1], b[0]),
 	).Float64()
 	return f
 }
@@ -202,17 +160,12 @@ func Mul(a, b Point) Point {
 }
 
 func Magn(a Point) float64 {
-	f, _ := new(big.Float).Sqrt(
-		new(big.Float).Add(
-			new(big.Float).Mul(a[0], a[0]),
-			new(big.Float).Mul(a[1], a[1]),
-		),
-	)
+	f, _ := new(big.Float).Sqrt(lengthSquared(a)).Float64()
 	return f
 }
 
 func DivideC(a Point, c float64) Point {
-	fc = new(big.Float).SetFloat64(c).SetPrec(precision).SetMode(big.ToZero)
+	fc := new(big.Float).SetFloat64(c).SetPrec(precision).SetMode(big.ToZero)
 	return Point{
 		new(big.Float).Quo(a[0], fc),
 		new(big.Float).Quo(a[1], fc),
@@ -38,21 +38,9 @@ func IsPointOn(l Line, pt Point) bool {
 		return false
 	}
 
-	// vertical line
-	if cmp.Float(x1, x2) {
-		return x1 == pt[0] &&
-			y1 <= pt[1] && pt[1] <= y2
-	}
-
-	// horizontal line
-	if cmp.Float(y1, y2) {
-		return y1 == pt[1] &&
-			x1 <= pt[0] && pt[0] <= x2
-	}
-
-	// Match the gradients
-	return cmp.Float((x1-pt[0])*(y1-pt[1]), (pt[0]-x2)*(pt[1]-y2))
-
+	// Within the extent of the line, so the point is on the line
+	// if it is colinear with the end points.
+	return Orientation(l[0], l[1], pt) == 0
 }
 
 // ArePointsEqual return if the two points are equal
@@ -62,19 +50,27 @@ func ArePointsEqual(a, b Point) bool {
 
 // TriArea reaturns twice the area of the oriented triangle (a,b,c), i.e.
 // the area is positive if the triangle is oriented counterclockwise.
+// The sign of the result is exact, see orient2d.
 func TriArea(a, b, c Point) float64 {
-	return (b[0]-a[0])*(c[1]-a[1]) - (b[1]-a[1])*(c[0]-a[0])
+	return orient2d(a, b, c)
 }
 
 // InCircle indicates weather the point d is inside the circle defined by the points
 // a,b,c. See Guibas and Stolf (1985) p.107
+// The result is exact, see incircle.
 func InCircle(a, b, c, d Point) bool {
+	return inCircle(a, b, c, d) > 0
+}
 
-	return (a[0]*a[0]+a[1]*a[1])*TriArea(b, c, d)-
-		(b[0]*b[0]+b[1]*b[1])*TriArea(a, c, d)+
-		(c[0]*c[0]+c[1]*c[1])*TriArea(a, b, d)-
-		(d[0]*d[0]+d[1]*d[1])*TriArea(a, b, c) > 0
+// inCircle returns the sign of the InCircle determinant.
+func inCircle(a, b, c, d Point) int {
+	return sign(incircle(a, b, c, d))
+}
 
+// cmpXY compares the x and y coordinates of a and b; each value
+// will be -1, 0, or 1 if a's coordinate is less, equal, or greater.
+func cmpXY(a, b Point) (x, y int) {
+	return sign(a[0] - b[0]), sign(a[1] - b[1])
 }
 
 func CrossProduct(a, b Point) float64 {
@@ -98,3 +94,21 @@ func Mul(a, b Point) Point {
 func Magn(a Point) float64 {
 	return math.Sqrt((a[0] * a[0]) + (a[1] * a[1]))
 }
+
+func Add(a, b Point) Point {
+	return Point{
+		a[0] + b[0],
+		a[1] + b[1],
+	}
+}
+
+func Dot(a, b Point) float64 {
+	return (a[0] * b[0]) + (a[1] * b[1])
+}
+
+func DivideC(a Point, c float64) Point {
+	return Point{
+		a[0] / c,
+		a[1] / c,
+	}
+}
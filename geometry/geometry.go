@@ -1,11 +1,84 @@
 package geometry
 
+import "sort"
 
 // CCW returns if the points a,b,c, are in a counterclockwise order
 func CCW(a, b, c Point) bool {
 	return TriArea(a, b, c) > 0
 }
 
+// Orientation returns 1 if the points a,b,c are in a counterclockwise order,
+// -1 if they are in a clockwise order, and 0 if they are colinear.
+func Orientation(a, b, c Point) int {
+	return sign(TriArea(a, b, c))
+}
+
+// PerturbedInCircle is like InCircle, but breaks ties for cocircular points
+// by symbolically perturbing the lifted (x²+y²) coordinate of each point,
+// lexicographically larger points by larger amounts. This is the scheme
+// described by Devillers and Teillaud (2011), "Perturbations for Delaunay
+// and weighted Delaunay 3D Triangulations". The points a,b,c should be in
+// counterclockwise order.
+func PerturbedInCircle(a, b, c, d Point) bool {
+	return KernelPerturbedInCircle(BackendKernel{}, a, b, c, d)
+}
+
+// KernelPerturbedInCircle is PerturbedInCircle using the predicates
+// of the given kernel.
+func KernelPerturbedInCircle(k Kernel, a, b, c, d Point) bool {
+	if s := k.InCircle(a, b, c, d); s != 0 {
+		return s > 0
+	}
+
+	pts := [4]Point{a, b, c, d}
+	idx := [4]int{0, 1, 2, 3}
+	sort.Slice(idx[:], func(i, j int) bool {
+		return comparePoints(pts[idx[i]], pts[idx[j]]) < 0
+	})
+
+	// Only the two largest points need to be looked at.
+	for i := 3; i > 1; i-- {
+		var o int
+		switch idx[i] {
+		case 3:
+			// a, b, c are not colinear and the perturbation moves d out.
+			return false
+		case 2:
+			o = k.Orientation(a, b, d)
+		case 1:
+			o = k.Orientation(a, d, c)
+		case 0:
+			o = k.Orientation(d, b, c)
+		}
+		if o != 0 {
+			return o > 0
+		}
+	}
+	// a, b, c must be colinear.
+	return false
+}
+
+// comparePoints orders the points lexicographically returning -1, 0, or 1 if
+// a is less, equal or greater then b.
+func comparePoints(a, b Point) int {
+	x, y := cmpXY(a, b)
+	if x != 0 {
+		return x
+	}
+	return y
+}
+
+func sign(f float64) int {
+	switch {
+	case f > 0:
+		return 1
+	case f < 0:
+		return -1
+	default:
+		return 0
+	}
+}
+
 func Extent(pts ...[2]float64) (ext [2][2]float64) {
 	if len(pts) == 0 {
 		return ext
@@ -57,7 +130,7 @@ func TriangleContaining(pts ...[2]float64) (tri [3][2]float64) {
 }
 
 func AppendNonRepeat(pts []Point, v Point) []Point {
-	if len(pts) == 0 || !ArePointsEqual(pts[len(pts)-1],v) {
+	if len(pts) == 0 || !ArePointsEqual(pts[len(pts)-1], v) {
 		return append(pts, v)
 	}
 	return pts
@@ -0,0 +1,32 @@
+package geometry
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestPerturbedInCircle(t *testing.T) {
+	type tcase struct {
+		// a, b, c, d should be a convex quadrilateral in ccw order.
+		a, b, c, d Point
+	}
+	fn := func(tc tcase) func(*testing.T) {
+		return func(t *testing.T) {
+			// Exactly one of the diagonals should be Delaunay.
+			ac := !PerturbedInCircle(tc.a, tc.b, tc.c, tc.d) && !PerturbedInCircle(tc.c, tc.d, tc.a, tc.b)
+			bd := !PerturbedInCircle(tc.b, tc.c, tc.d, tc.a) && !PerturbedInCircle(tc.d, tc.a, tc.b, tc.c)
+			if ac == bd {
+				t.Errorf("diagonals, expected one of them got ac: %v bd: %v", ac, bd)
+			}
+		}
+	}
+	tests := []tcase{
+		{a: NewPoint(0, 0), b: NewPoint(10, 0), c: NewPoint(10, 10), d: NewPoint(0, 10)},
+		{a: NewPoint(10, 0), b: NewPoint(10, 10), c: NewPoint(0, 10), d: NewPoint(0, 0)},
+		{a: NewPoint(0, 5), b: NewPoint(-3, -4), c: NewPoint(4, -3), d: NewPoint(5, 0)},
+		{a: NewPoint(0, 0), b: NewPoint(10, 0), c: NewPoint(10, 10), d: NewPoint(0, 11)},
+	}
+	for i, tc := range tests {
+		t.Run(strconv.Itoa(i), fn(tc))
+	}
+}
@@ -2,7 +2,10 @@
 
 package geometry
 
-import "math"
+import (
+	"math"
+	"math/big"
+)
 
 type Point [2]int64
 
@@ -36,21 +39,9 @@ func IsPointOn(l Line, pt Point) bool {
 		return false
 	}
 
-	// vertical line
-	if x1 == x2 {
-		return x1 == pt[0] &&
-			y1 <= pt[1] && pt[1] <= y2
-	}
-
-	// horizontal line
-	if y1 == y2 {
-		return y1 == pt[1] &&
-			x1 <= pt[0] && pt[0] <= x2
-	}
-
-	// Match the gradients
-	return (x1-pt[0])*(y1-pt[1]) == (pt[0]-x2)*(pt[1]-y2)
-
+	// Within the extent of the line, so the point is on the line
+	// if it is colinear with the end points.
+	return Orientation(l[0], l[1], pt) == 0
 }
 
 // ArePointsEqual return if the two points are equal
@@ -60,34 +51,47 @@ func ArePointsEqual(a, b Point) bool {
 
 // TriArea reaturns twice the area of the oriented triangle (a,b,c), i.e.
 // the area is positive if the triangle is oriented counterclockwise.
+// The sign of the result is exact.
 func TriArea(a, b, c Point) float64 {
-	return float64(triArea(a, b, c))
-}
-
-func triArea(a, b, c Point) int64 {
-	return (b[0]-a[0])*(c[1]-a[1]) - (b[1]-a[1])*(c[0]-a[0])
+	if withinInt64(maxTriAreaCoord, a, b, c) {
+		return float64(triAreaInt64(a, b, c))
+	}
+	f, _ := new(big.Float).SetInt(bigTriArea(a, b, c)).Float64()
+	return f
 }
 
 // InCircle indicates weather the point d is inside the circle defined by the points
 // a,b,c. See Guibas and Stolf (1985) p.107
 func InCircle(a, b, c, d Point) bool {
+	return inCircle(a, b, c, d) > 0
+}
 
-	return (a[0]*a[0]+a[1]*a[1])*triArea(b, c, d)-
-		(b[0]*b[0]+b[1]*b[1])*triArea(a, c, d)+
-		(c[0]*c[0]+c[1]*c[1])*triArea(a, b, d)-
-		(d[0]*d[0]+d[1]*d[1])*triArea(a, b, c) > 0
+// inCircle returns the sign of the InCircle determinant.
+func inCircle(a, b, c, d Point) int {
+	return inCircleInt64(a, b, c, d)
+}
 
+// cmpXY compares the x and y coordinates of a and b; each value
+// will be -1, 0, or 1 if a's coordinate is less, equal, or greater.
+func cmpXY(a, b Point) (x, y int) {
+	return cmpInt64(a[0], b[0]), cmpInt64(a[1], b[1])
 }
 
 func CrossProduct(a, b Point) float64 {
-	return float64((a[0] * b[0]) - (a[1] * b[1]))
+	return (float64(a[0]) * float64(b[1])) - (float64(a[1]) * float64(b[0]))
+}
+
+func Dot(a, b Point) float64 {
+	return (float64(a[0]) * float64(b[0])) + (float64(a[1]) * float64(b[1]))
 }
+
 func Sub(a, b Point) Point {
 	return Point{
 		a[0] - b[0],
 		a[1] - b[1],
 	}
 }
+
 func Add(a, b Point) Point {
 	return Point{
 		a[0] + b[0],
@@ -103,5 +107,11 @@ func Mul(a, b Point) Point {
 }
 
 func Magn(a Point) float64 {
-	return math.Sqrt(float64((a[0] * a[0]) + (a[1] * a[1])))
+	return math.Hypot(float64(a[0]), float64(a[1]))
+}
+
+// DivideC divides each coordinate of a by c, the result is truncated
+// like NewPoint.
+func DivideC(a Point, c float64) Point {
+	return NewPoint(float64(a[0])/c, float64(a[1])/c)
 }
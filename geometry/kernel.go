@@ -0,0 +1,292 @@
+package geometry
+
+import (
+	"math"
+	"math/big"
+)
+
+// Kernel provides the geometric predicates used to build a triangulation.
+// Unlike the Point type, which is selected by build tags, a Kernel can be
+// picked at runtime, so one binary can triangulate integer tile coordinates
+// exactly and also handle float lon/lat data.
+type Kernel interface {
+	// Orientation returns 1 if the points a,b,c are in a counterclockwise order,
+	// -1 if they are in a clockwise order, and 0 if they are colinear.
+	Orientation(a, b, c Point) int
+	// InCircle returns 1 if the point d is inside the circle defined by the
+	// counterclockwise points a,b,c, -1 if it is outside and 0 if it is on
+	// the circle.
+	InCircle(a, b, c, d Point) int
+	// ArePointsEqual return if the two points are equal
+	ArePointsEqual(a, b Point) bool
+	// IsPointOn returns if the point is on the line segment.
+	IsPointOn(l Line, pt Point) bool
+}
+
+// PointKeyer is implemented by kernels that treat points with different
+// coordinates as equal; PointKey returns the same key for all the points
+// ArePointsEqual says are equal.
+type PointKeyer interface {
+	PointKey(pt Point) [2]float64
+}
+
+// PointKey returns the key of pt in maps keyed by points, using the kernel's
+// PointKey if it is a PointKeyer, and the coordinates of pt otherwise.
+func PointKey(k Kernel, pt Point) [2]float64 {
+	if pk, ok := k.(PointKeyer); ok {
+		return pk.PointKey(pt)
+	}
+	return UnwrapPoint(pt)
+}
+
+// BackendKernel uses the predicates of the Point type selected by the
+// build tags. It is the default kernel.
+type BackendKernel struct{}
+
+func (BackendKernel) Orientation(a, b, c Point) int   { return Orientation(a, b, c) }
+func (BackendKernel) InCircle(a, b, c, d Point) int   { return inCircle(a, b, c, d) }
+func (BackendKernel) ArePointsEqual(a, b Point) bool  { return ArePointsEqual(a, b) }
+func (BackendKernel) IsPointOn(l Line, pt Point) bool { return IsPointOn(l, pt) }
+
+// FloatKernel works on the float64 values of the points, using the
+// adaptive exact predicates. Points are only equal if their coordinates
+// are exactly the same.
+type FloatKernel struct{}
+
+func (FloatKernel) Orientation(a, b, c Point) int {
+	return sign(orient2d(UnwrapPoint(a), UnwrapPoint(b), UnwrapPoint(c)))
+}
+
+func (FloatKernel) InCircle(a, b, c, d Point) int {
+	return sign(incircle(UnwrapPoint(a), UnwrapPoint(b), UnwrapPoint(c), UnwrapPoint(d)))
+}
+
+func (FloatKernel) ArePointsEqual(a, b Point) bool {
+	return UnwrapPoint(a) == UnwrapPoint(b)
+}
+
+func (k FloatKernel) IsPointOn(l Line, pt Point) bool {
+	if !inExtent(UnwrapPoint(l[0]), UnwrapPoint(l[1]), UnwrapPoint(pt)) {
+		return false
+	}
+	return k.Orientation(l[0], l[1], pt) == 0
+}
+
+// inExtent returns if pt is within the extent of the line segment a,b.
+func inExtent(a, b, pt [2]float64) bool {
+	ext := Extent(a, b)
+	return ext[0][0] <= pt[0] && pt[0] <= ext[1][0] &&
+		ext[0][1] <= pt[1] && pt[1] <= ext[1][1]
+}
+
+// IntKernel rounds the coordinates of the points to the nearest integer,
+// and computes the predicates exactly on those integers. Coordinates
+// outside of the int64 range are clamped to it, and NaN is taken as 0.
+type IntKernel struct{}
+
+func intPoint(pt Point) [2]int64 {
+	upt := UnwrapPoint(pt)
+	return [2]int64{roundInt64(upt[0]), roundInt64(upt[1])}
+}
+
+// roundInt64 rounds x to the nearest int64, clamping it to the int64 range;
+// converting an out of range float64 to an int64 is implementation
+// dependent.
+func roundInt64(x float64) int64 {
+	switch x = math.Round(x); {
+	case math.IsNaN(x):
+		return 0
+	case x >= math.MaxInt64:
+		// float64(math.MaxInt64) is 2^63, the first value out of range.
+		return math.MaxInt64
+	case x < math.MinInt64:
+		return math.MinInt64
+	default:
+		return int64(x)
+	}
+}
+
+func (IntKernel) Orientation(a, b, c Point) int {
+	return orientInt64(intPoint(a), intPoint(b), intPoint(c))
+}
+
+func (IntKernel) InCircle(a, b, c, d Point) int {
+	return inCircleInt64(intPoint(a), intPoint(b), intPoint(c), intPoint(d))
+}
+
+func (IntKernel) ArePointsEqual(a, b Point) bool {
+	return intPoint(a) == intPoint(b)
+}
+
+// PointKey returns the rounded coordinates of pt.
+func (IntKernel) PointKey(pt Point) [2]float64 {
+	ipt := intPoint(pt)
+	return [2]float64{float64(ipt[0]), float64(ipt[1])}
+}
+
+func (IntKernel) IsPointOn(l Line, pt Point) bool {
+	a, b, p := intPoint(l[0]), intPoint(l[1]), intPoint(pt)
+	for i := range p {
+		lo, hi := a[i], b[i]
+		if lo > hi {
+			lo, hi = hi, lo
+		}
+		if p[i] < lo || hi < p[i] {
+			return false
+		}
+	}
+	return orientInt64(a, b, p) == 0
+}
+
+// The int64 determinants are only computed directly when all the
+// coordinates are within these bounds, otherwise they could overflow
+// and are computed with big.Int instead.
+const (
+	maxTriAreaCoord  = 1 << 30
+	maxInCircleCoord = 1 << 13
+)
+
+// withinInt64 returns if all the coordinates of the points are in (-max,max).
+func withinInt64(max int64, pts ...[2]int64) bool {
+	for _, pt := range pts {
+		if pt[0] <= -max || max <= pt[0] || pt[1] <= -max || max <= pt[1] {
+			return false
+		}
+	}
+	return true
+}
+
+func cmpInt64(i, j int64) int {
+	switch {
+	case i < j:
+		return -1
+	case i > j:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// orientInt64 returns the sign of twice the area of the triangle a,b,c.
+func orientInt64(a, b, c [2]int64) int {
+	if withinInt64(maxTriAreaCoord, a, b, c) {
+		return cmpInt64(triAreaInt64(a, b, c), 0)
+	}
+	return bigTriArea(a, b, c).Sign()
+}
+
+func triAreaInt64(a, b, c [2]int64) int64 {
+	return (b[0]-a[0])*(c[1]-a[1]) - (b[1]-a[1])*(c[0]-a[0])
+}
+
+func bigTriArea(a, b, c [2]int64) *big.Int {
+	bax := new(big.Int).Sub(big.NewInt(b[0]), big.NewInt(a[0]))
+	bay := new(big.Int).Sub(big.NewInt(b[1]), big.NewInt(a[1]))
+	cax := new(big.Int).Sub(big.NewInt(c[0]), big.NewInt(a[0]))
+	cay := new(big.Int).Sub(big.NewInt(c[1]), big.NewInt(a[1]))
+	return new(big.Int).Sub(
+		new(big.Int).Mul(bax, cay),
+		new(big.Int).Mul(bay, cax),
+	)
+}
+
+// inCircleInt64 returns the sign of the InCircle determinant.
+func inCircleInt64(a, b, c, d [2]int64) int {
+	if !withinInt64(maxInCircleCoord, a, b, c, d) {
+		return bigInCircle(a, b, c, d)
+	}
+	adx, ady := a[0]-d[0], a[1]-d[1]
+	bdx, bdy := b[0]-d[0], b[1]-d[1]
+	cdx, cdy := c[0]-d[0], c[1]-d[1]
+	det := (adx*adx+ady*ady)*(bdx*cdy-bdy*cdx) +
+		(bdx*bdx+bdy*bdy)*(cdx*ady-cdy*adx) +
+		(cdx*cdx+cdy*cdy)*(adx*bdy-ady*bdx)
+	return cmpInt64(det, 0)
+}
+
+func bigInCircle(a, b, c, d [2]int64) int {
+	diff := func(p [2]int64) (x, y *big.Int) {
+		return new(big.Int).Sub(big.NewInt(p[0]), big.NewInt(d[0])),
+			new(big.Int).Sub(big.NewInt(p[1]), big.NewInt(d[1]))
+	}
+	lift := func(x, y *big.Int) *big.Int {
+		return new(big.Int).Add(new(big.Int).Mul(x, x), new(big.Int).Mul(y, y))
+	}
+	det2 := func(ax, ay, bx, by *big.Int) *big.Int {
+		return new(big.Int).Sub(new(big.Int).Mul(ax, by), new(big.Int).Mul(ay, bx))
+	}
+	adx, ady := diff(a)
+	bdx, bdy := diff(b)
+	cdx, cdy := diff(c)
+	det := new(big.Int).Mul(lift(adx, ady), det2(bdx, bdy, cdx, cdy))
+	det.Add(det, new(big.Int).Mul(lift(bdx, bdy), det2(cdx, cdy, adx, ady)))
+	det.Add(det, new(big.Int).Mul(lift(cdx, cdy), det2(adx, ady, bdx, bdy)))
+	return det.Sign()
+}
+
+// BigFloatKernel computes the predicates on the float64 values of the
+// points with big.Float numbers of the given precision. If Prec is 0,
+// DefaultBigFloatPrec is used.
+type BigFloatKernel struct {
+	Prec uint
+}
+
+// DefaultBigFloatPrec is enough precision for the orientation and
+// incircle determinants of float64 coordinates of similar magnitude.
+const DefaultBigFloatPrec = 256
+
+func (k BigFloatKernel) newFloat() *big.Float {
+	if k.Prec == 0 {
+		return new(big.Float).SetPrec(DefaultBigFloatPrec)
+	}
+	return new(big.Float).SetPrec(k.Prec)
+}
+
+func (k BigFloatKernel) point(pt Point) (x, y *big.Float) {
+	upt := UnwrapPoint(pt)
+	return k.newFloat().SetFloat64(upt[0]), k.newFloat().SetFloat64(upt[1])
+}
+
+// det2 returns ax*by - ay*bx
+func (k BigFloatKernel) det2(ax, ay, bx, by *big.Float) *big.Float {
+	return k.newFloat().Sub(k.newFloat().Mul(ax, by), k.newFloat().Mul(ay, bx))
+}
+
+func (k BigFloatKernel) Orientation(a, b, c Point) int {
+	ax, ay := k.point(a)
+	bx, by := k.point(b)
+	cx, cy := k.point(c)
+	return k.det2(
+		k.newFloat().Sub(ax, cx), k.newFloat().Sub(ay, cy),
+		k.newFloat().Sub(bx, cx), k.newFloat().Sub(by, cy),
+	).Sign()
+}
+
+func (k BigFloatKernel) InCircle(a, b, c, d Point) int {
+	dx, dy := k.point(d)
+	diff := func(p Point) (x, y *big.Float) {
+		px, py := k.point(p)
+		return k.newFloat().Sub(px, dx), k.newFloat().Sub(py, dy)
+	}
+	lift := func(x, y *big.Float) *big.Float {
+		return k.newFloat().Add(k.newFloat().Mul(x, x), k.newFloat().Mul(y, y))
+	}
+	adx, ady := diff(a)
+	bdx, bdy := diff(b)
+	cdx, cdy := diff(c)
+	det := k.newFloat().Mul(lift(adx, ady), k.det2(bdx, bdy, cdx, cdy))
+	det.Add(det, k.newFloat().Mul(lift(bdx, bdy), k.det2(cdx, cdy, adx, ady)))
+	det.Add(det, k.newFloat().Mul(lift(cdx, cdy), k.det2(adx, ady, bdx, bdy)))
+	return det.Sign()
+}
+
+func (BigFloatKernel) ArePointsEqual(a, b Point) bool {
+	return UnwrapPoint(a) == UnwrapPoint(b)
+}
+
+func (k BigFloatKernel) IsPointOn(l Line, pt Point) bool {
+	if !inExtent(UnwrapPoint(l[0]), UnwrapPoint(l[1]), UnwrapPoint(pt)) {
+		return false
+	}
+	return k.Orientation(l[0], l[1], pt) == 0
+}
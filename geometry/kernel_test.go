@@ -0,0 +1,145 @@
+package geometry
+
+import (
+	"math"
+	"math/rand"
+	"strconv"
+	"testing"
+)
+
+func TestKernel(t *testing.T) {
+	kernels := map[string]Kernel{
+		"backend":  BackendKernel{},
+		"float":    FloatKernel{},
+		"int":      IntKernel{},
+		"bigfloat": BigFloatKernel{},
+	}
+	type tcase struct {
+		a, b, c, d [2]float64
+	}
+	fn := func(k Kernel, tc tcase) func(*testing.T) {
+		return func(t *testing.T) {
+			a, b, c, d := NewPoint(tc.a[0], tc.a[1]), NewPoint(tc.b[0], tc.b[1]), NewPoint(tc.c[0], tc.c[1]), NewPoint(tc.d[0], tc.d[1])
+			if expected, got := exactOrient2d(tc.a, tc.b, tc.c), k.Orientation(a, b, c); got != expected {
+				t.Errorf("orientation, expected %v got %v", expected, got)
+			}
+			if expected, got := exactIncircle(tc.a, tc.b, tc.c, tc.d), k.InCircle(a, b, c, d); got != expected {
+				t.Errorf("incircle, expected %v got %v", expected, got)
+			}
+			if !k.ArePointsEqual(a, NewPoint(tc.a[0], tc.a[1])) {
+				t.Errorf("equal, expected true got false")
+			}
+		}
+	}
+
+	// Integer points, that are exact for all the backends.
+	tests := []tcase{
+		{a: [2]float64{0, 0}, b: [2]float64{4, 0}, c: [2]float64{0, 4}, d: [2]float64{1, 1}},
+		{a: [2]float64{0, 0}, b: [2]float64{4, 0}, c: [2]float64{4, 4}, d: [2]float64{0, 4}},
+		{a: [2]float64{0, 0}, b: [2]float64{1, 1}, c: [2]float64{2, 2}, d: [2]float64{3, 3}},
+	}
+	rnd := rand.New(rand.NewSource(1))
+	for _, max := range []int64{1 << 10, 1 << 20, 1 << 40} {
+		for i := 0; i < 64; i++ {
+			p := func() [2]float64 {
+				return [2]float64{float64(rnd.Int63n(max) - max/2), float64(rnd.Int63n(max) - max/2)}
+			}
+			a, b := p(), p()
+			// c is colinear with a and b
+			c := [2]float64{2*b[0] - a[0], 2*b[1] - a[1]}
+			tests = append(tests,
+				tcase{a: a, b: b, c: p(), d: p()},
+				tcase{a: a, b: b, c: c, d: p()},
+			)
+		}
+	}
+	for name, k := range kernels {
+		for i, tc := range tests {
+			t.Run(name+"/"+strconv.Itoa(i), fn(k, tc))
+		}
+	}
+}
+
+func TestKernelIsPointOn(t *testing.T) {
+	type tcase struct {
+		l        [2][2]float64
+		pt       [2]float64
+		expected bool
+	}
+	tests := []tcase{
+		{l: [2][2]float64{{0, 0}, {10, 10}}, pt: [2]float64{5, 5}, expected: true},
+		{l: [2][2]float64{{0, 0}, {10, 10}}, pt: [2]float64{0, 0}, expected: true},
+		{l: [2][2]float64{{0, 0}, {10, 10}}, pt: [2]float64{11, 11}, expected: false},
+		{l: [2][2]float64{{0, 0}, {10, 10}}, pt: [2]float64{5, 6}, expected: false},
+		{l: [2][2]float64{{0, 0}, {20, 10}}, pt: [2]float64{20, 0}, expected: false},
+		{l: [2][2]float64{{10, 0}, {0, 0}}, pt: [2]float64{3, 0}, expected: true},
+	}
+	for name, k := range map[string]Kernel{
+		"backend":  BackendKernel{},
+		"float":    FloatKernel{},
+		"int":      IntKernel{},
+		"bigfloat": BigFloatKernel{},
+	} {
+		for i, tc := range tests {
+			l := Line{NewPoint(tc.l[0][0], tc.l[0][1]), NewPoint(tc.l[1][0], tc.l[1][1])}
+			if got := k.IsPointOn(l, NewPoint(tc.pt[0], tc.pt[1])); got != tc.expected {
+				t.Errorf("%v/%v: is point on, expected %v got %v", name, i, tc.expected, got)
+			}
+		}
+	}
+}
+
+func TestPointKey(t *testing.T) {
+	pairs := [][2][2]float64{
+		{{1, 3}, {1, 3}},
+		{{1.2, 3}, {1, 3}},
+		{{0.6, -0.4}, {1, 0}},
+		{{1, 3}, {2, 3}},
+	}
+	for name, k := range map[string]Kernel{
+		"float":    FloatKernel{},
+		"int":      IntKernel{},
+		"bigfloat": BigFloatKernel{},
+	} {
+		for i, pair := range pairs {
+			a, b := NewPoint(pair[0][0], pair[0][1]), NewPoint(pair[1][0], pair[1][1])
+			equal := PointKey(k, a) == PointKey(k, b)
+			if expected := k.ArePointsEqual(a, b); equal != expected {
+				t.Errorf("%v/%v: equal keys, expected %v got %v", name, i, expected, equal)
+			}
+		}
+	}
+}
+
+func TestRoundInt64(t *testing.T) {
+	tests := []struct {
+		x        float64
+		expected int64
+	}{
+		{x: 0, expected: 0},
+		{x: 1.5, expected: 2},
+		{x: -1.5, expected: -2},
+		{x: 1 << 62, expected: 1 << 62},
+		{x: -(1 << 63), expected: math.MinInt64},
+		{x: 1 << 63, expected: math.MaxInt64},
+		{x: 1e300, expected: math.MaxInt64},
+		{x: -1e300, expected: math.MinInt64},
+		{x: math.Inf(1), expected: math.MaxInt64},
+		{x: math.Inf(-1), expected: math.MinInt64},
+		{x: math.NaN(), expected: 0},
+	}
+	for i, tc := range tests {
+		if got := roundInt64(tc.x); got != tc.expected {
+			t.Errorf("%v: round %v, expected %v got %v", i, tc.x, tc.expected, got)
+		}
+	}
+	// Points out of range are clamped, so the ones past the same corner
+	// are equal, and the predicates stay consistent.
+	k := IntKernel{}
+	if !k.ArePointsEqual(NewPoint(1e19, -1e19), NewPoint(1e300, math.Inf(-1))) {
+		t.Errorf("clamped points equal, expected true got false")
+	}
+	if got := k.Orientation(NewPoint(0, 0), NewPoint(1e20, 0), NewPoint(0, 1e20)); got != 1 {
+		t.Errorf("orientation, expected 1 got %v", got)
+	}
+}
@@ -0,0 +1,369 @@
+package geometry
+
+// The predicates in this file are adapted from Jonathan Richard Shewchuk's
+// "Adaptive Precision Floating-Point Arithmetic and Fast Robust Geometric
+// Predicates" (1997). Each predicate first evaluates the determinant with
+// ordinary float64 arithmetic, and only when the result falls within the
+// forward error bound does it fall back to (progressively more) exact
+// expansion arithmetic. The sign of the returned value is always correct.
+//
+// Note: Go is allowed to fuse a*b+c into a single FMA instruction, which
+// would break the error-free transformations below. The explicit float64()
+// conversions force the intermediate products to be rounded.
+
+const (
+	// epsilon is the largest power of two such that 1.0 + epsilon = 1.0 in
+	// float64 round-to-even arithmetic.
+	epsilon = 1.0 / (1 << 53)
+	// splitter is used to split a float64 into two half length values for
+	// exact multiplication.
+	splitter = (1 << 27) + 1
+
+	resultErrBound = (3.0 + 8.0*epsilon) * epsilon
+	ccwErrBoundA   = (3.0 + 16.0*epsilon) * epsilon
+	ccwErrBoundB   = (2.0 + 12.0*epsilon) * epsilon
+	ccwErrBoundC   = (9.0 + 64.0*epsilon) * epsilon * epsilon
+	iccErrBoundA   = (10.0 + 96.0*epsilon) * epsilon
+	iccErrBoundB   = (4.0 + 48.0*epsilon) * epsilon
+)
+
+/******** Error-free transformations *******************************************/
+
+// fastTwoSum returns x = fl(a+b) and the roundoff error y; |a| >= |b|.
+func fastTwoSum(a, b float64) (x, y float64) {
+	x = a + b
+	bvirt := x - a
+	y = b - bvirt
+	return x, y
+}
+
+// twoSum returns x = fl(a+b) and the roundoff error y.
+func twoSum(a, b float64) (x, y float64) {
+	x = a + b
+	bvirt := x - a
+	avirt := x - bvirt
+	bround := b - bvirt
+	around := a - avirt
+	y = around + bround
+	return x, y
+}
+
+// twoDiffTail returns the roundoff error of x = fl(a-b).
+func twoDiffTail(a, b, x float64) float64 {
+	bvirt := a - x
+	avirt := x + bvirt
+	bround := bvirt - b
+	around := a - avirt
+	return around + bround
+}
+
+// twoDiff returns x = fl(a-b) and the roundoff error y.
+func twoDiff(a, b float64) (x, y float64) {
+	x = a - b
+	return x, twoDiffTail(a, b, x)
+}
+
+// split a into two non-overlapping 26 bit values such that a = hi + lo.
+func split(a float64) (hi, lo float64) {
+	c := float64(splitter * a)
+	abig := c - a
+	hi = c - abig
+	lo = a - hi
+	return hi, lo
+}
+
+// twoProduct returns x = fl(a*b) and the roundoff error y.
+func twoProduct(a, b float64) (x, y float64) {
+	x = float64(a * b)
+	ahi, alo := split(a)
+	bhi, blo := split(b)
+	err1 := x - float64(ahi*bhi)
+	err2 := err1 - float64(alo*bhi)
+	err3 := err2 - float64(ahi*blo)
+	y = float64(alo*blo) - err3
+	return x, y
+}
+
+/******** Expansion arithmetic *************************************************/
+
+// An expansion is a sum of non-overlapping float64 components, ordered by
+// increasing magnitude. All of the functions below eliminate zero components,
+// but will always return at least one component.
+
+// expansion returns the two component expansion of x + y, where y is the
+// roundoff error of x.
+func expansion(x, y float64) []float64 {
+	if y == 0 {
+		return []float64{x}
+	}
+	return []float64{y, x}
+}
+
+// expansionSum returns the expansion h = e + f.
+// Shewchuk's fast_expansion_sum_zeroelim.
+func expansionSum(e, f []float64) []float64 {
+	var (
+		h          = make([]float64, 0, len(e)+len(f))
+		eidx, fidx int
+		q, hh      float64
+	)
+	// next returns the next smallest magnitude component of e and f.
+	next := func() (v float64) {
+		if fidx >= len(f) || (eidx < len(e) && (f[fidx] > e[eidx]) == (f[fidx] > -e[eidx])) {
+			v, eidx = e[eidx], eidx+1
+			return v
+		}
+		v, fidx = f[fidx], fidx+1
+		return v
+	}
+
+	q = next()
+	if eidx < len(e) && fidx < len(f) {
+		q, hh = fastTwoSum(next(), q)
+		if hh != 0 {
+			h = append(h, hh)
+		}
+	}
+	for eidx < len(e) || fidx < len(f) {
+		q, hh = twoSum(q, next())
+		if hh != 0 {
+			h = append(h, hh)
+		}
+	}
+	if q != 0 || len(h) == 0 {
+		h = append(h, q)
+	}
+	return h
+}
+
+// scaleExpansion returns the expansion h = e * b.
+// Shewchuk's scale_expansion_zeroelim.
+func scaleExpansion(e []float64, b float64) []float64 {
+	h := make([]float64, 0, 2*len(e))
+	q, hh := twoProduct(e[0], b)
+	if hh != 0 {
+		h = append(h, hh)
+	}
+	for _, enow := range e[1:] {
+		p1, p0 := twoProduct(enow, b)
+		sum, hh := twoSum(q, p0)
+		if hh != 0 {
+			h = append(h, hh)
+		}
+		q, hh = fastTwoSum(p1, sum)
+		if hh != 0 {
+			h = append(h, hh)
+		}
+	}
+	if q != 0 || len(h) == 0 {
+		h = append(h, q)
+	}
+	return h
+}
+
+// expansionProduct returns the expansion h = e * f.
+func expansionProduct(e, f []float64) []float64 {
+	h := scaleExpansion(e, f[0])
+	for _, fnow := range f[1:] {
+		h = expansionSum(h, scaleExpansion(e, fnow))
+	}
+	return h
+}
+
+// negateExpansion returns the expansion -e.
+func negateExpansion(e []float64) []float64 {
+	h := make([]float64, len(e))
+	for i := range e {
+		h[i] = -e[i]
+	}
+	return h
+}
+
+// estimate returns a float64 approximation of the expansion.
+func estimate(e []float64) float64 {
+	var q float64
+	for _, v := range e {
+		q += v
+	}
+	return q
+}
+
+// mostSignificant returns the largest component of the expansion, which
+// carries the sign of the expansion.
+func mostSignificant(e []float64) float64 { return e[len(e)-1] }
+
+// det2Expansion returns the exact expansion of ax*by - ay*bx.
+func det2Expansion(ax, ay, bx, by []float64) []float64 {
+	return expansionSum(
+		expansionProduct(ax, by),
+		negateExpansion(expansionProduct(ay, bx)),
+	)
+}
+
+/******** Predicates ***********************************************************/
+
+// orient2d returns a positive value if the points a, b, and c are in
+// counterclockwise order, a negative value if they are in clockwise order,
+// and zero if they are colinear. The magnitude is approximately twice the
+// area of the triangle, the sign is exact.
+func orient2d(a, b, c [2]float64) float64 {
+	detleft := float64((a[0] - c[0]) * (b[1] - c[1]))
+	detright := float64((a[1] - c[1]) * (b[0] - c[0]))
+	det := detleft - detright
+
+	var detsum float64
+	switch {
+	case detleft > 0:
+		if detright <= 0 {
+			return det
+		}
+		detsum = detleft + detright
+	case detleft < 0:
+		if detright >= 0 {
+			return det
+		}
+		detsum = -detleft - detright
+	default:
+		return det
+	}
+
+	errbound := ccwErrBoundA * detsum
+	if det >= errbound || -det >= errbound {
+		return det
+	}
+	return orient2dAdapt(a, b, c, detsum)
+}
+
+func orient2dAdapt(a, b, c [2]float64, detsum float64) float64 {
+	acx, acy := a[0]-c[0], a[1]-c[1]
+	bcx, bcy := b[0]-c[0], b[1]-c[1]
+
+	// Stage B: exact products of the rounded differences.
+	detleft, detlefttail := twoProduct(acx, bcy)
+	detright, detrighttail := twoProduct(acy, bcx)
+	bexp := expansionSum(
+		expansion(detleft, detlefttail),
+		expansion(-detright, -detrighttail),
+	)
+	det := estimate(bexp)
+	errbound := ccwErrBoundB * detsum
+	if det >= errbound || -det >= errbound {
+		return det
+	}
+
+	acxtail := twoDiffTail(a[0], c[0], acx)
+	bcxtail := twoDiffTail(b[0], c[0], bcx)
+	acytail := twoDiffTail(a[1], c[1], acy)
+	bcytail := twoDiffTail(b[1], c[1], bcy)
+	if acxtail == 0 && acytail == 0 && bcxtail == 0 && bcytail == 0 {
+		// The differences were exact, so stage B was exact.
+		return mostSignificant(bexp)
+	}
+
+	// Stage C: first order correction for the roundoff in the differences.
+	errbound = ccwErrBoundC*detsum + resultErrBound*abs(det)
+	det += float64(float64(acx*bcytail)+float64(bcy*acxtail)) -
+		float64(float64(acy*bcxtail)+float64(bcx*acytail))
+	if det >= errbound || -det >= errbound {
+		return det
+	}
+
+	// Stage D: exact.
+	return mostSignificant(det2Expansion(
+		expansion(acx, acxtail),
+		expansion(acy, acytail),
+		expansion(bcx, bcxtail),
+		expansion(bcy, bcytail),
+	))
+}
+
+// incircle returns a positive value if the point d lies inside the circle
+// passing through a, b, and c; a negative value if it lies outside; and zero
+// if the four points are cocircular. The points a, b, and c must be in
+// counterclockwise order, or the sign of the result will be reversed.
+func incircle(a, b, c, d [2]float64) float64 {
+	adx, ady := a[0]-d[0], a[1]-d[1]
+	bdx, bdy := b[0]-d[0], b[1]-d[1]
+	cdx, cdy := c[0]-d[0], c[1]-d[1]
+
+	bdxcdy, cdxbdy := float64(bdx*cdy), float64(cdx*bdy)
+	alift := float64(adx*adx) + float64(ady*ady)
+
+	cdxady, adxcdy := float64(cdx*ady), float64(adx*cdy)
+	blift := float64(bdx*bdx) + float64(bdy*bdy)
+
+	adxbdy, bdxady := float64(adx*bdy), float64(bdx*ady)
+	clift := float64(cdx*cdx) + float64(cdy*cdy)
+
+	det := float64(alift*(bdxcdy-cdxbdy)) +
+		float64(blift*(cdxady-adxcdy)) +
+		float64(clift*(adxbdy-bdxady))
+
+	permanent := float64((abs(bdxcdy)+abs(cdxbdy))*alift) +
+		float64((abs(cdxady)+abs(adxcdy))*blift) +
+		float64((abs(adxbdy)+abs(bdxady))*clift)
+	errbound := iccErrBoundA * permanent
+	if det > errbound || -det > errbound {
+		return det
+	}
+	return incircleAdapt(a, b, c, d, permanent)
+}
+
+func incircleAdapt(a, b, c, d [2]float64, permanent float64) float64 {
+	adx, ady := a[0]-d[0], a[1]-d[1]
+	bdx, bdy := b[0]-d[0], b[1]-d[1]
+	cdx, cdy := c[0]-d[0], c[1]-d[1]
+
+	// Stage B: exact arithmetic on the rounded differences.
+	bexp := incircleExpansion(
+		[]float64{adx}, []float64{ady},
+		[]float64{bdx}, []float64{bdy},
+		[]float64{cdx}, []float64{cdy},
+	)
+	det := estimate(bexp)
+	errbound := iccErrBoundB * permanent
+	if det >= errbound || -det >= errbound {
+		return det
+	}
+
+	adxtail := twoDiffTail(a[0], d[0], adx)
+	adytail := twoDiffTail(a[1], d[1], ady)
+	bdxtail := twoDiffTail(b[0], d[0], bdx)
+	bdytail := twoDiffTail(b[1], d[1], bdy)
+	cdxtail := twoDiffTail(c[0], d[0], cdx)
+	cdytail := twoDiffTail(c[1], d[1], cdy)
+	if adxtail == 0 && bdxtail == 0 && cdxtail == 0 &&
+		adytail == 0 && bdytail == 0 && cdytail == 0 {
+		// The differences were exact, so stage B was exact.
+		return mostSignificant(bexp)
+	}
+
+	// Stage C: exact.
+	return mostSignificant(incircleExpansion(
+		expansion(adx, adxtail), expansion(ady, adytail),
+		expansion(bdx, bdxtail), expansion(bdy, bdytail),
+		expansion(cdx, cdxtail), expansion(cdy, cdytail),
+	))
+}
+
+// incircleExpansion returns the exact expansion of the incircle determinant
+// given the (expansions of the) differences of a, b, c with d.
+func incircleExpansion(adx, ady, bdx, bdy, cdx, cdy []float64) []float64 {
+	lift := func(x, y []float64) []float64 {
+		return expansionSum(expansionProduct(x, x), expansionProduct(y, y))
+	}
+	return expansionSum(
+		expansionSum(
+			expansionProduct(lift(adx, ady), det2Expansion(bdx, bdy, cdx, cdy)),
+			expansionProduct(lift(bdx, bdy), det2Expansion(cdx, cdy, adx, ady)),
+		),
+		expansionProduct(lift(cdx, cdy), det2Expansion(adx, ady, bdx, bdy)),
+	)
+}
+
+func abs(f float64) float64 {
+	if f < 0 {
+		return -f
+	}
+	return f
+}
@@ -0,0 +1,123 @@
+package geometry
+
+import (
+	"math"
+	"math/big"
+	"math/rand"
+	"strconv"
+	"testing"
+)
+
+func exactOrient2d(a, b, c [2]float64) int {
+	r := func(f float64) *big.Rat { return new(big.Rat).SetFloat64(f) }
+	acx := new(big.Rat).Sub(r(a[0]), r(c[0]))
+	acy := new(big.Rat).Sub(r(a[1]), r(c[1]))
+	bcx := new(big.Rat).Sub(r(b[0]), r(c[0]))
+	bcy := new(big.Rat).Sub(r(b[1]), r(c[1]))
+	return new(big.Rat).Sub(
+		new(big.Rat).Mul(acx, bcy),
+		new(big.Rat).Mul(acy, bcx),
+	).Sign()
+}
+
+func exactIncircle(a, b, c, d [2]float64) int {
+	r := func(f float64) *big.Rat { return new(big.Rat).SetFloat64(f) }
+	diff := func(p [2]float64) (x, y *big.Rat) {
+		return new(big.Rat).Sub(r(p[0]), r(d[0])), new(big.Rat).Sub(r(p[1]), r(d[1]))
+	}
+	adx, ady := diff(a)
+	bdx, bdy := diff(b)
+	cdx, cdy := diff(c)
+	lift := func(x, y *big.Rat) *big.Rat {
+		return new(big.Rat).Add(new(big.Rat).Mul(x, x), new(big.Rat).Mul(y, y))
+	}
+	det2 := func(ax, ay, bx, by *big.Rat) *big.Rat {
+		return new(big.Rat).Sub(new(big.Rat).Mul(ax, by), new(big.Rat).Mul(ay, bx))
+	}
+	det := new(big.Rat).Mul(lift(adx, ady), det2(bdx, bdy, cdx, cdy))
+	det.Add(det, new(big.Rat).Mul(lift(bdx, bdy), det2(cdx, cdy, adx, ady)))
+	det.Add(det, new(big.Rat).Mul(lift(cdx, cdy), det2(adx, ady, bdx, bdy)))
+	return det.Sign()
+}
+
+func TestOrient2d(t *testing.T) {
+	type tcase struct {
+		a, b, c [2]float64
+	}
+	fn := func(tc tcase) func(*testing.T) {
+		return func(t *testing.T) {
+			expected := exactOrient2d(tc.a, tc.b, tc.c)
+			if got := sign(orient2d(tc.a, tc.b, tc.c)); got != expected {
+				t.Errorf("sign, expected %v got %v", expected, got)
+			}
+		}
+	}
+	tests := []tcase{
+		{a: [2]float64{0, 0}, b: [2]float64{1, 0}, c: [2]float64{0, 1}},
+		{a: [2]float64{0, 0}, b: [2]float64{0, 1}, c: [2]float64{1, 0}},
+		{a: [2]float64{0, 0}, b: [2]float64{1, 1}, c: [2]float64{2, 2}},
+		{a: [2]float64{0.5, 0.5}, b: [2]float64{12, 12}, c: [2]float64{24, 24}},
+		{a: [2]float64{4160, 949.667}, b: [2]float64{4160, 3091}, c: [2]float64{4160, 2642}},
+	}
+	// Points near the line y = x, Kettner et al. "Classroom Examples of
+	// Robustness Problems in Geometric Computations"
+	for i := 0; i < 64; i++ {
+		for j := 0; j < 64; j++ {
+			tests = append(tests, tcase{
+				a: [2]float64{0.5 + float64(i)*math.Pow(2, -53), 0.5 + float64(j)*math.Pow(2, -53)},
+				b: [2]float64{12, 12},
+				c: [2]float64{24, 24},
+			})
+		}
+	}
+	for i, tc := range tests {
+		t.Run(strconv.Itoa(i), fn(tc))
+	}
+}
+
+func TestIncircle(t *testing.T) {
+	type tcase struct {
+		a, b, c, d [2]float64
+	}
+	fn := func(tc tcase) func(*testing.T) {
+		return func(t *testing.T) {
+			expected := exactIncircle(tc.a, tc.b, tc.c, tc.d)
+			if got := sign(incircle(tc.a, tc.b, tc.c, tc.d)); got != expected {
+				t.Errorf("sign, expected %v got %v", expected, got)
+			}
+		}
+	}
+	tests := []tcase{
+		{a: [2]float64{0, 0}, b: [2]float64{1, 0}, c: [2]float64{0, 1}, d: [2]float64{0.25, 0.25}},
+		{a: [2]float64{0, 0}, b: [2]float64{1, 0}, c: [2]float64{0, 1}, d: [2]float64{2, 2}},
+		// cocircular
+		{a: [2]float64{0, 0}, b: [2]float64{1, 0}, c: [2]float64{1, 1}, d: [2]float64{0, 1}},
+		{a: [2]float64{-64, -64}, b: [2]float64{4160, -64}, c: [2]float64{4160, 4160}, d: [2]float64{-64, 4160}},
+	}
+	// Nearly cocircular points; perturbations of a square's corners.
+	rnd := rand.New(rand.NewSource(1))
+	for i := 0; i < 1024; i++ {
+		p := func(x, y float64) [2]float64 {
+			return [2]float64{
+				x + float64(rnd.Intn(5)-2)*math.Pow(2, -50),
+				y + float64(rnd.Intn(5)-2)*math.Pow(2, -50),
+			}
+		}
+		tests = append(tests, tcase{
+			a: p(0.1, 0.1),
+			b: p(1.1, 0.1),
+			c: p(1.1, 1.1),
+			d: p(0.1, 1.1),
+		})
+	}
+	for i, tc := range tests {
+		t.Run(strconv.Itoa(i), fn(tc))
+	}
+}
+
+func TestExpansionSum(t *testing.T) {
+	e := expansionSum([]float64{math.Pow(2, -60), 1}, []float64{-1})
+	if len(e) != 1 || e[0] != math.Pow(2, -60) {
+		t.Errorf("expansion, expected [%v] got %v", math.Pow(2, -60), e)
+	}
+}
@@ -6,6 +6,8 @@ import (
 	"fmt"
 	"io/ioutil"
 	"log"
+	"math"
+	"math/rand"
 	"path/filepath"
 	"sort"
 	"strconv"
@@ -253,6 +255,49 @@ func TestTriangulation(t *testing.T) {
 
 }
 
+// BenchmarkNewForPoints compares the insertion orders of NewForPoints.
+func BenchmarkNewForPoints(b *testing.B) {
+	orders := []subdivision.InsertionOrder{
+		subdivision.ORDER_LEXICOGRAPHIC,
+		subdivision.ORDER_RANDOM,
+		subdivision.ORDER_HILBERT_BRIO,
+	}
+	for _, n := range []int{1000, 10000, 100000} {
+		rnd := rand.New(rand.NewSource(1))
+		pts := make([][2]float64, n)
+		for i := range pts {
+			pts[i] = [2]float64{float64(rnd.Intn(1 << 20)), float64(rnd.Intn(1 << 20))}
+		}
+		for _, order := range orders {
+			b.Run(fmt.Sprintf("%v/%v", order, n), func(b *testing.B) {
+				ctx := context.Background()
+				for i := 0; i < b.N; i++ {
+					subdivision.NewForPoints(ctx, append([][2]float64(nil), pts...), subdivision.WithInsertionOrder(order))
+				}
+			})
+		}
+	}
+}
+
+// BenchmarkNewForPointsDC compares the number of workers of NewForPointsDC.
+func BenchmarkNewForPointsDC(b *testing.B) {
+	for _, n := range []int{1000, 10000, 100000} {
+		rnd := rand.New(rand.NewSource(1))
+		pts := make([][2]float64, n)
+		for i := range pts {
+			pts[i] = [2]float64{float64(rnd.Intn(1 << 20)), float64(rnd.Intn(1 << 20))}
+		}
+		for _, workers := range []int{1, 2, 4, 8} {
+			b.Run(fmt.Sprintf("workers_%v/%v", workers, n), func(b *testing.B) {
+				ctx := context.Background()
+				for i := 0; i < b.N; i++ {
+					subdivision.NewForPointsDC(ctx, append([][2]float64(nil), pts...), workers)
+				}
+			})
+		}
+	}
+}
+
 func TestConstraint(t *testing.T) {
 
 	fn := func(tc qetriangulate.Constrained) func(*testing.T) {
@@ -8860,3 +8905,173 @@ func TestConstraint(t *testing.T) {
 		t.Run(name, fn(tc))
 	}
 }
+
+func TestConstrainedSplitIntersecting(t *testing.T) {
+	ctx := context.Background()
+	tc := qetriangulate.GeomConstrained{
+		Points: []geom.Point{{0, 10}, {10, 0}, {5, 12}, {5, -2}},
+		Constraints: []geom.Line{
+			{{0, 0}, {10, 10}},
+			{{0, 10}, {10, 0}},
+		},
+	}
+	if _, err := tc.Triangles(ctx, false); err == nil {
+		t.Errorf("crossing constraints, expected error got nil")
+	}
+
+	tc.SplitIntersecting = true
+	tris, err := tc.Triangles(ctx, false)
+	if err != nil {
+		t.Fatalf("crossing constraints, expected nil got %v", err)
+	}
+	if len(tc.Steiner) != 1 || !cmp.PointEqual(tc.Steiner[0], geom.Point{5, 5}) {
+		t.Errorf("steiner points, expected [[5 5]] got %v", tc.Steiner)
+	}
+	// Each half of the constraints should be a triangle edge.
+	for _, half := range []geom.Line{{{0, 0}, {5, 5}}, {{5, 5}, {10, 10}}, {{0, 10}, {5, 5}}, {{5, 5}, {10, 0}}} {
+		found := false
+		for _, tri := range tris {
+			for i := range tri {
+				a, b := tri[i], tri[(i+1)%3]
+				if (cmp.PointEqual(a, half[0]) && cmp.PointEqual(b, half[1])) ||
+					(cmp.PointEqual(a, half[1]) && cmp.PointEqual(b, half[0])) {
+					found = true
+				}
+			}
+		}
+		if !found {
+			t.Errorf("edge %v, expected a triangle edge got nothing", half)
+		}
+	}
+}
+
+func TestConforming(t *testing.T) {
+	ctx := context.Background()
+	tc := qetriangulate.Conforming{
+		Points:      []geom.Point{{0, 5}, {5, -5}, {10, 5}, {15, -5}, {20, 5}},
+		Constraints: []geom.Line{{{-5, 0}, {25, 0}}},
+	}
+	tris, err := tc.Triangles(ctx, false)
+	if err != nil {
+		t.Fatalf("triangles, expected nil got %v", err)
+	}
+	if len(tc.Steiner) == 0 {
+		t.Errorf("steiner points, expected some got none")
+	}
+
+	// The constraint should be covered by triangle edges.
+	var length float64
+	for _, tri := range tris {
+		for i := range tri {
+			a, b := tri[i], tri[(i+1)%3]
+			if a[1] == 0 && b[1] == 0 {
+				length += math.Abs(b[0] - a[0])
+			}
+		}
+	}
+	// Each edge, except the ones on the hull, is in two triangles.
+	if length != 2*30 {
+		t.Errorf("length of edges on the constraint, expected %v got %v", 2*30, length)
+	}
+
+	// The result should be the Delaunay triangulation of the points.
+	pts := append([]geom.Point{{-5, 0}, {25, 0}}, tc.Points...)
+	pts = append(pts, tc.Steiner...)
+	for _, tri := range tris {
+		if geometry.Orientation(
+			geometry.NewPoint(tri[0][0], tri[0][1]),
+			geometry.NewPoint(tri[1][0], tri[1][1]),
+			geometry.NewPoint(tri[2][0], tri[2][1]),
+		) < 0 {
+			tri[1], tri[2] = tri[2], tri[1]
+		}
+		for _, pt := range pts {
+			if tri[0] == pt || tri[1] == pt || tri[2] == pt {
+				continue
+			}
+			if geometry.InCircle(
+				geometry.NewPoint(tri[0][0], tri[0][1]),
+				geometry.NewPoint(tri[1][0], tri[1][1]),
+				geometry.NewPoint(tri[2][0], tri[2][1]),
+				geometry.NewPoint(pt[0], pt[1]),
+			) {
+				t.Errorf("triangle %v, expected empty circumcircle got %v in it", tri, pt)
+			}
+		}
+	}
+}
+
+func TestRefine(t *testing.T) {
+	opts := qetriangulate.RefineOptions{
+		Points:      []geom.Point{{0, 0}, {100, 0}, {100, 100}, {0, 100}, {50, 1}},
+		Constraints: []geom.Line{{{10, 50}, {90, 55}}},
+	}
+	opts.MinAngle = 20
+	opts.MaxArea = 200
+
+	tris, steiner, err := qetriangulate.Refine(context.Background(), opts)
+	if err != nil {
+		t.Fatalf("refine, expected nil got %v", err)
+	}
+	if len(steiner) == 0 {
+		t.Errorf("steiner points, expected some got none")
+	}
+	var total float64
+	for _, tri := range tris {
+		area := math.Abs((tri[1][0]-tri[0][0])*(tri[2][1]-tri[0][1])-(tri[1][1]-tri[0][1])*(tri[2][0]-tri[0][0])) / 2
+		total += area
+		if area > opts.MaxArea {
+			t.Errorf("triangle %v, expected area <= %v got %v", tri, opts.MaxArea, area)
+		}
+	}
+	// The triangles should cover the square.
+	if math.Abs(total-100*100) > 1e-6 {
+		t.Errorf("area of triangles, expected %v got %v", 100*100, total)
+	}
+}
+
+func TestTriangulatePolygon(t *testing.T) {
+	polygon := geom.Polygon{
+		{{0, 0}, {100, 0}, {100, 100}, {0, 100}, {0, 0}},
+		{{20, 20}, {20, 60}, {60, 60}, {60, 20}, {20, 20}},
+	}
+	area := func(tris []geom.Triangle) (area float64) {
+		for _, tri := range tris {
+			area += math.Abs((tri[1][0]-tri[0][0])*(tri[2][1]-tri[0][1])-(tri[1][1]-tri[0][1])*(tri[2][0]-tri[0][0])) / 2
+		}
+		return area
+	}
+
+	tris, err := qetriangulate.TriangulatePolygon(context.Background(), polygon)
+	if err != nil {
+		t.Fatalf("triangulate polygon, expected nil got %v", err)
+	}
+	if expected := 100.0*100 - 40*40; area(tris) != expected {
+		t.Errorf("area, expected %v got %v", expected, area(tris))
+	}
+
+	// An L shape, the triangles in the concave part are outside.
+	lshape := geom.Polygon{{{200, 0}, {300, 0}, {300, 50}, {250, 50}, {250, 100}, {200, 100}}}
+	tris, err = qetriangulate.TriangulateMultiPolygon(context.Background(), geom.MultiPolygon{polygon, lshape})
+	if err != nil {
+		t.Fatalf("triangulate multipolygon, expected nil got %v", err)
+	}
+	if expected := 100.0*100 - 40*40 + 100*50 + 50*50; area(tris) != expected {
+		t.Errorf("area, expected %v got %v", expected, area(tris))
+	}
+	// The polygon, with the closing points of the rings off of the
+	// integer grid; the integer kernel rounds them to the first points.
+	var shifted geom.Polygon
+	for _, ring := range polygon {
+		sr := append([][2]float64{}, ring[:len(ring)-1]...)
+		sr = append(sr, [2]float64{ring[0][0] + 0.3, ring[0][1] + 0.2})
+		shifted = append(shifted, sr)
+	}
+	tris, err = qetriangulate.PolygonTriangulator{Kernel: geometry.IntKernel{}}.Triangulate(context.Background(), shifted)
+	if err != nil {
+		t.Fatalf("triangulate polygon with the int kernel, expected nil got %v", err)
+	}
+	if expected := 100.0*100 - 40*40; area(tris) != expected {
+		t.Errorf("area with the int kernel, expected %v got %v", expected, area(tris))
+	}
+}
@@ -13,6 +13,9 @@ type Edge struct {
 	next *Edge
 	qe   *QuadEdge
 	v    *geometry.Point
+	// data is the data of the origin of the edge; of a vertex for
+	// primal edges, and of a face for dual edges.
+	data interface{}
 }
 
 // NewEdge will return a new edge that is part of an QuadEdge
@@ -48,6 +51,66 @@ func (e *Edge) Dest() *geometry.Point {
 func (e *Edge) EndPoints(org, dest *geometry.Point) {
 	e.v = org
 	e.Sym().v = dest
+	if e.qe != nil && e.qe.face != nil {
+		updateFace(e)
+		updateFace(e.Sym())
+	}
+}
+
+// OrigData returns the data of the origin of the edge.
+func (e *Edge) OrigData() interface{} {
+	if e == nil {
+		return nil
+	}
+	return e.data
+}
+
+// DestData returns the data of the destination of the edge.
+func (e *Edge) DestData() interface{} {
+	return e.Sym().OrigData()
+}
+
+// EndPointsData sets the data of the origin and destination of the edge.
+// Like EndPoints, only e and e.Sym() are changed; the data of a vertex should
+// be the same on all the edges from it. Connect and Swap carry the data
+// of the vertices over to the edges they change.
+func (e *Edge) EndPointsData(org, dest interface{}) {
+	e.data = org
+	e.Sym().data = dest
+}
+
+// Data returns the data of the edge, it is shared by all the edges of the
+// QuadEdge. It stays with the edge through Swap.
+func (e *Edge) Data() interface{} {
+	if e == nil || e.qe == nil {
+		return nil
+	}
+	return e.qe.data
+}
+
+// SetData sets the data of the edge, see Data.
+func (e *Edge) SetData(data interface{}) {
+	if e == nil || e.qe == nil {
+		return
+	}
+	e.qe.data = data
+}
+
+// IsConstrained returns if the edge is marked as a constraint.
+func (e *Edge) IsConstrained() bool {
+	if e == nil || e.qe == nil {
+		return false
+	}
+	return e.qe.constrained
+}
+
+// SetConstrained marks, or un-marks, the edge as a constraint. As the
+// mark is on the QuadEdge, it applies to e.Sym() as well.
+func (e *Edge) SetConstrained(constrained bool) {
+	if e == nil || e.qe == nil {
+		return
+	}
+	e.qe.constrained = constrained
 }
 
 /******** Edge Algebra *********************************************************/
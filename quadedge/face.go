@@ -0,0 +1,55 @@
+package quadedge
+
+import "github.com/gdey/quad-edge/geometry"
+
+// FaceFunc returns the dual vertex, and any other data, of the face on the
+// left of e. It is called as edges are spliced, so the face may not be a
+// triangle, and it's vertices may not be set yet.
+//
+// The dual vertex is the origin of the dual edges from the face; so
+// e.InvRot().Orig() is the dual vertex of the face on the left of e, and
+// e.Rot().Orig() the one on the right.
+type FaceFunc func(e *Edge) (vertex *geometry.Point, data interface{})
+
+// SetFaceFunc sets the function used to keep the data of the faces up to
+// date on the dual edges of e, and updates the faces on either side of e.
+// Edges spliced to e get the function as well; but the faces of edges
+// already connected to e are only kept up to date if it is set on those
+// edges too.
+func (e *Edge) SetFaceFunc(fn FaceFunc) {
+	if e == nil || e.qe == nil {
+		return
+	}
+	e.qe.face = fn
+	if fn == nil {
+		return
+	}
+	updateFace(e)
+	updateFace(e.Sym())
+}
+
+// FaceData returns the data of the face on the left of e, see FaceFunc.
+func (e *Edge) FaceData() interface{} {
+	if e == nil {
+		return nil
+	}
+	return e.InvRot().data
+}
+
+// updateFace sets the dual vertex, and data, of the face on the left of the
+// primal edge e, on the dual edges from the face.
+func updateFace(e *Edge) {
+	if e.num%2 != 0 || e.qe.face == nil {
+		// The left of a dual edge is a vertex, not a face.
+		return
+	}
+	v, data := e.qe.face(e)
+	for curr := e; ; {
+		// InvRot is directed from the left of curr.
+		d := curr.InvRot()
+		d.v, d.data = v, data
+		if curr = curr.LNext(); curr == e {
+			return
+		}
+	}
+}
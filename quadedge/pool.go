@@ -0,0 +1,227 @@
+package quadedge
+
+import (
+	"math"
+
+	"github.com/gdey/quad-edge/geometry"
+)
+
+// slabSize is the number of QuadEdges, or points, a Pool allocates at a time.
+const slabSize = 1024
+
+// EdgeRef is a 32-bit reference to an edge in a Pool; the index of it's
+// QuadEdge, with which of the four edges of the QuadEdge it is in the low two
+// bits. See Pool.Ref and Pool.Edge. It is a compact handle to keep edges in
+// tables outside of the pool; the edges themselves still link to each other,
+// and to their vertices, with pointers.
+type EdgeRef uint32
+
+// NilRef does not refer to any edge.
+const NilRef = EdgeRef(math.MaxUint32)
+
+// maxQuadEdges is the number of QuadEdges an EdgeRef can refer to.
+const maxQuadEdges = math.MaxUint32 >> 2
+
+// Pool allocates QuadEdges, and the points of their vertices, in slabs; so
+// a large subdivision is a few large allocations, not millions of small
+// ones. The slabs are never moved, so the edges of a Pool are used like any
+// other edges, with Rot, Sym, ONext and the rest; and with Splice, Connect,
+// and the other operators. QuadEdges given back with Free are reused by New,
+// and points given back with FreePoint are reused by Point.
+//
+// Only the allocation changes, the edges are the same as the ones from New;
+// the garbage collector still has to follow their pointers.
+//
+// The zero value is an empty Pool. A nil Pool allocates each edge, and point,
+// on it's own, like New. A Pool is not safe for concurrent use.
+type Pool struct {
+	slabs []*[slabSize]QuadEdge
+	// count is the number of QuadEdges taken from the slabs.
+	count uint32
+	// free holds the ids of the QuadEdges that have been freed.
+	free []uint32
+
+	points  []*[slabSize]geometry.Point
+	npoints int
+	// freePoints holds the points that have been freed.
+	freePoints []*geometry.Point
+}
+
+// NewPool returns an empty Pool.
+func NewPool() *Pool {
+	return new(Pool)
+}
+
+func (p *Pool) qedge(id uint32) *QuadEdge {
+	return &p.slabs[id/slabSize][id%slabSize]
+}
+
+// owns returns if e is an edge of the pool.
+func (p *Pool) owns(e *Edge) bool {
+	return p != nil && e != nil && e.qe != nil && e.qe.id < p.count && p.qedge(e.qe.id) == e.qe
+}
+
+// New returns a new edge from the pool, like the New function; reusing a
+// freed QuadEdge if there is one.
+func (p *Pool) New() *Edge {
+	if p == nil {
+		return New()
+	}
+	var qe *QuadEdge
+	if n := len(p.free); n > 0 {
+		qe = p.qedge(p.free[n-1])
+		p.free = p.free[:n-1]
+	} else {
+		if p.count == maxQuadEdges {
+			panic("quadedge: pool is full")
+		}
+		if int(p.count/slabSize) == len(p.slabs) {
+			p.slabs = append(p.slabs, new([slabSize]QuadEdge))
+		}
+		qe = p.qedge(p.count)
+		qe.id = p.count
+		p.count++
+	}
+	qe.init()
+	return &qe.e[0]
+}
+
+// Merge moves the QuadEdges, and points, of other in to p, leaving other
+// empty; so edges can be made in separate pools concurrently, then used as
+// one. The edges of other do not move, but their EdgeRefs change. The rest
+// of the last slab of p is freed, to be reused by New.
+func (p *Pool) Merge(other *Pool) {
+	if other == nil || other == p || (other.count == 0 && other.npoints == 0) {
+		return
+	}
+	// The QuadEdges of other start on a slab boundary.
+	for ; p.count%slabSize != 0; p.count++ {
+		p.qedge(p.count).id = p.count
+		p.free = append(p.free, p.count)
+	}
+	if uint64(p.count)+uint64(other.count) > maxQuadEdges {
+		panic("quadedge: pool is full")
+	}
+	offset := p.count
+	for id := uint32(0); id < other.count; id++ {
+		other.qedge(id).id += offset
+	}
+	for _, id := range other.free {
+		p.free = append(p.free, id+offset)
+	}
+	p.slabs = append(p.slabs, other.slabs...)
+	p.count += other.count
+
+	// The rest of the last slab of points is not used.
+	p.npoints = len(p.points)*slabSize + other.npoints
+	p.points = append(p.points, other.points...)
+	p.freePoints = append(p.freePoints, other.freePoints...)
+	*other = Pool{}
+}
+
+// NewWithEndPoints returns a new edge from the pool from a to b.
+func (p *Pool) NewWithEndPoints(a, b *geometry.Point) *Edge {
+	e := p.New()
+	e.EndPoints(a, b)
+	return e
+}
+
+// Free gives the QuadEdge of e back to the pool, to be reused by New. The
+// edge should already be disconnected from any others, see Delete. It's end
+// points and data are cleared, and it is left as an edge on it's own until
+// it is reused. Edges that are not from the pool, or are already free, are
+// ignored.
+func (p *Pool) Free(e *Edge) {
+	if !p.owns(e) || !e.qe.initialized {
+		return
+	}
+	qe := e.qe
+	*qe = QuadEdge{id: qe.id}
+	qe.init()
+	qe.initialized = false
+	p.free = append(p.free, qe.id)
+}
+
+// Connect is Connect, with the new edge from the pool.
+func (p *Pool) Connect(a, b *Edge) *Edge {
+	return connect(p.New(), a, b)
+}
+
+// Delete is Delete, then Free; the QuadEdge of e is reused by New. Any
+// references to e, or it's Sym, Rot and InvRot, should not be used
+// afterwards. Edges not from the pool are only deleted.
+func (p *Pool) Delete(e *Edge) {
+	Delete(e)
+	p.Free(e)
+}
+
+// Len returns the number of QuadEdges of the pool in use.
+func (p *Pool) Len() int {
+	if p == nil {
+		return 0
+	}
+	return int(p.count) - len(p.free)
+}
+
+// Freed returns the number of QuadEdges that have been freed, and not
+// reused yet.
+func (p *Pool) Freed() int {
+	if p == nil {
+		return 0
+	}
+	return len(p.free)
+}
+
+// Ref returns the reference to e, or NilRef if e is not an edge of the pool.
+func (p *Pool) Ref(e *Edge) EdgeRef {
+	if !p.owns(e) {
+		return NilRef
+	}
+	return EdgeRef(e.qe.id<<2 | uint32(e.num))
+}
+
+// Edge returns the edge the reference refers to, or nil if it does not
+// refer to an edge in use.
+func (p *Pool) Edge(ref EdgeRef) *Edge {
+	id := uint32(ref >> 2)
+	if p == nil || ref == NilRef || id >= p.count {
+		return nil
+	}
+	qe := p.qedge(id)
+	if !qe.initialized {
+		return nil
+	}
+	return &qe.e[ref&3]
+}
+
+// Point returns a pointer to a copy of pt, kept in the pool, to be used as
+// the end point of edges; reusing a freed point if there is one. Points are
+// not freed with the edges, see FreePoint.
+func (p *Pool) Point(pt geometry.Point) *geometry.Point {
+	if p == nil {
+		return &pt
+	}
+	if n := len(p.freePoints); n > 0 {
+		v := p.freePoints[n-1]
+		p.freePoints = p.freePoints[:n-1]
+		*v = pt
+		return v
+	}
+	if p.npoints/slabSize == len(p.points) {
+		p.points = append(p.points, new([slabSize]geometry.Point))
+	}
+	v := &p.points[p.npoints/slabSize][p.npoints%slabSize]
+	*v = pt
+	p.npoints++
+	return v
+}
+
+// FreePoint gives the point back to the pool, to be reused by Point. The
+// point should no longer be the end point of any edge.
+func (p *Pool) FreePoint(v *geometry.Point) {
+	if p == nil || v == nil {
+		return
+	}
+	*v = geometry.Point{}
+	p.freePoints = append(p.freePoints, v)
+}
@@ -0,0 +1,175 @@
+package quadedge
+
+import (
+	"testing"
+
+	"github.com/gdey/quad-edge/geometry"
+)
+
+func TestPool(t *testing.T) {
+	var p Pool
+
+	// Enough edges to need more then one slab; the edges from the first
+	// slab should not move.
+	first := p.New()
+	edges := []*Edge{first}
+	for i := 1; i < 2*slabSize+10; i++ {
+		edges = append(edges, p.New())
+	}
+	if p.Len() != len(edges) {
+		t.Errorf("len, expected %v got %v", len(edges), p.Len())
+	}
+	if edges[0] != first || p.Edge(p.Ref(first)) != first {
+		t.Errorf("first edge, expected %p got %p", first, edges[0])
+	}
+	for i, e := range edges {
+		if e.Rot().Rot().Rot().Rot() != e || e.Sym().Sym() != e || e.ONext() != e || e.Rot().ONext() != e.InvRot() {
+			t.Errorf("edge %v, expected a new edge", i)
+		}
+		for _, re := range [4]*Edge{e, e.Rot(), e.Sym(), e.InvRot()} {
+			if got := p.Edge(p.Ref(re)); got != re {
+				t.Errorf("edge %v, expected ref to give %p got %p", i, re, got)
+			}
+		}
+	}
+
+	// A triangle made of edges from the pool.
+	a, b, c := p.Point(geometry.NewPoint(0, 0)), p.Point(geometry.NewPoint(10, 0)), p.Point(geometry.NewPoint(5, 10))
+	ea := p.NewWithEndPoints(a, b)
+	eb := p.NewWithEndPoints(b, c)
+	Splice(ea.Sym(), eb)
+	ec := p.Connect(eb, ea)
+	if ea.LNext() != eb || eb.LNext() != ec || ec.LNext() != ea {
+		t.Errorf("triangle, expected the edges to go around the face")
+	}
+	if !geometry.ArePointsEqual(*ec.Orig(), *c) || !geometry.ArePointsEqual(*ec.Dest(), *a) {
+		t.Errorf("connected edge, expected %v %v got %v %v", *c, *a, *ec.Orig(), *ec.Dest())
+	}
+
+	// Delete frees the edge, Connect reuses it.
+	live := p.Len()
+	p.Delete(ec)
+	if p.Len() != live-1 || p.Freed() != 1 {
+		t.Errorf("len and freed after delete, expected %v 1 got %v %v", live-1, p.Len(), p.Freed())
+	}
+	if ec.ONext() != ec || ec.Orig() != nil {
+		t.Errorf("deleted edge, expected an edge on it's own")
+	}
+	if got := p.Connect(eb, ea); got != ec || ec.LNext() != ea || p.Freed() != 0 {
+		t.Errorf("connect, expected the deleted edge %p got %p", ec, got)
+	}
+
+	// Freed edges are reused.
+	e := edges[5]
+	ref := p.Ref(e)
+	e.SetData("data")
+	p.Free(e)
+	p.Free(e)
+	if p.Len() != len(edges)+2 {
+		t.Errorf("len after free, expected %v got %v", len(edges)+2, p.Len())
+	}
+	if got := p.Edge(ref); got != nil {
+		t.Errorf("freed ref, expected nil got %p", got)
+	}
+	if got := p.New(); got != e || got.Data() != nil || got.Orig() != nil {
+		t.Errorf("new, expected the freed edge %p, cleared, got %p", e, got)
+	}
+	if p.Edge(ref) != e {
+		t.Errorf("reused ref, expected %p got %p", e, p.Edge(ref))
+	}
+
+	// Edges not from the pool.
+	if ref := p.Ref(New()); ref != NilRef {
+		t.Errorf("ref of an edge not in the pool, expected NilRef got %v", ref)
+	}
+	if got := p.Edge(NilRef); got != nil {
+		t.Errorf("edge of NilRef, expected nil got %p", got)
+	}
+}
+
+func TestPoolPoint(t *testing.T) {
+	var p Pool
+	a, b := p.Point(geometry.NewPoint(1, 2)), p.Point(geometry.NewPoint(3, 4))
+	if a == b || !geometry.ArePointsEqual(*a, geometry.NewPoint(1, 2)) {
+		t.Errorf("points, expected two points got %v %v", *a, *b)
+	}
+	// Freed points are reused.
+	p.FreePoint(a)
+	if got := p.Point(geometry.NewPoint(5, 6)); got != a || !geometry.ArePointsEqual(*a, geometry.NewPoint(5, 6)) {
+		t.Errorf("point, expected the freed point %p got %p", a, got)
+	}
+	if got := p.Point(geometry.NewPoint(7, 8)); got == a || got == b {
+		t.Errorf("point, expected a new point got %p", got)
+	}
+}
+
+func TestPoolMerge(t *testing.T) {
+	var p, other Pool
+	for i := 0; i < 10; i++ {
+		p.New()
+	}
+	var edges []*Edge
+	for i := 0; i < slabSize+10; i++ {
+		edges = append(edges, other.New())
+	}
+	other.Free(edges[3])
+	pt := other.Point(geometry.NewPoint(1, 2))
+
+	p.Merge(&other)
+	if other.Len() != 0 || other.Freed() != 0 {
+		t.Errorf("other after merge, expected empty got %v %v", other.Len(), other.Freed())
+	}
+	// The rest of the first slab of p, and the edge freed in other.
+	if live, freed := p.Len(), p.Freed(); live != 10+len(edges)-1 || freed != slabSize-10+1 {
+		t.Errorf("len and freed, expected %v %v got %v %v", 10+len(edges)-1, slabSize-10+1, live, freed)
+	}
+	for i, e := range edges {
+		if i == 3 {
+			continue
+		}
+		if got := p.Edge(p.Ref(e)); got != e {
+			t.Errorf("edge %v, expected ref to give %p got %p", i, e, got)
+		}
+	}
+	if got := p.New(); got != edges[3] {
+		t.Errorf("new, expected the edge freed in other %p got %p", edges[3], got)
+	}
+	if got := p.Point(geometry.NewPoint(3, 4)); got == pt {
+		t.Errorf("point, expected a new point got the point of other")
+	}
+}
+
+func TestNilPool(t *testing.T) {
+	var p *Pool
+	a, b := p.Point(geometry.NewPoint(0, 0)), p.Point(geometry.NewPoint(10, 0))
+	e := p.NewWithEndPoints(a, b)
+	if e.Orig() != a || e.Dest() != b || e.ONext() != e {
+		t.Errorf("edge, expected a new edge from %v to %v", *a, *b)
+	}
+	if ref := p.Ref(e); ref != NilRef {
+		t.Errorf("ref, expected NilRef got %v", ref)
+	}
+	p.Delete(e)
+	p.FreePoint(a)
+	if p.Len() != 0 || p.Freed() != 0 {
+		t.Errorf("len and freed, expected 0 0 got %v %v", p.Len(), p.Freed())
+	}
+}
+
+func BenchmarkPoolNew(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		var p Pool
+		for j := 0; j < 100000; j++ {
+			p.New()
+		}
+	}
+}
+
+func BenchmarkNew(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		edges := make([]*Edge, 0, 100000)
+		for j := 0; j < 100000; j++ {
+			edges = append(edges, New())
+		}
+	}
+}
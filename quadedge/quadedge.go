@@ -2,13 +2,30 @@ package quadedge
 
 type QuadEdge struct {
 	initialized bool
-	e           [4]Edge
+	// constrained is set if the edge is a constraint, and should not
+	// be swapped or deleted to restore the Delaunay property.
+	constrained bool
+	// id is the index of the QuadEdge in it's Pool, see EdgeRef.
+	id uint32
+	// face, if not nil, keeps the data of the faces on the dual edges
+	// up to date; see FaceFunc.
+	face FaceFunc
+	// data is the data of the edge, see Edge.Data.
+	data interface{}
+	e    [4]Edge
 }
 
 func NewQEdge() *QuadEdge {
 	var qe QuadEdge
+	qe.init()
+	return &qe
+}
+
+// init sets up the QuadEdge as an edge, and it's dual, that are not
+// connected to anything.
+func (qe *QuadEdge) init() {
 	qe.e[0].num, qe.e[1].num, qe.e[2].num, qe.e[3].num = 0, 1, 2, 3
-	qe.e[0].qe, qe.e[1].qe, qe.e[2].qe, qe.e[3].qe = &qe, &qe, &qe, &qe
+	qe.e[0].qe, qe.e[1].qe, qe.e[2].qe, qe.e[3].qe = qe, qe, qe, qe
 
 	qe.e[0].next = &(qe.e[0])
 	qe.e[1].next = &(qe.e[3])
@@ -16,6 +33,4 @@ func NewQEdge() *QuadEdge {
 	qe.e[3].next = &(qe.e[1])
 
 	qe.initialized = true
-
-	return &qe
 }
@@ -1,8 +1,6 @@
 package quadedge
 
 import (
-	"log"
-
 	"github.com/gdey/quad-edge/geometry"
 )
 
@@ -14,6 +12,8 @@ import (
 // Thus, Splice can be used both to attach the two edges together, and
 // to break them apart. See Guibas and Stolfi (1985) p.96 for more details
 // and illustrations.
+// If either a or b has a FaceFunc, both get it, and the data of the faces
+// on the left of a and b are updated.
 func Splice(a, b *Edge) {
 	if a == nil || b == nil {
 		return
@@ -30,22 +30,40 @@ func Splice(a, b *Edge) {
 	b.next = t2
 	alpha.next = t3
 	beta.next = t4
+
+	// The faces on the left of a and b may have changed.
+	face := a.qe.face
+	if face == nil {
+		face = b.qe.face
+	}
+	if face != nil {
+		a.qe.face, b.qe.face = face, face
+		updateFace(a)
+		updateFace(b)
+	}
 }
 
 // Connect Add a new edge e connection the destination of a to the
 // origin of b, in such a way that all three have the same
 // left face after the connection is complete.
-// Additionally, the data pointers of the new edge are set.
+// Additionally, the data pointers of the new edge, and the data of it's
+// vertices, are set; and, if a has a FaceFunc, the data of the faces.
 func Connect(a, b *Edge) *Edge {
-	e := New()
+	return connect(New(), a, b)
+}
+
+// connect is Connect, using the new edge e.
+func connect(e, a, b *Edge) *Edge {
 	Splice(e, a.LNext())
 	Splice(e.Sym(), b)
+	e.EndPointsData(a.DestData(), b.OrigData())
 	e.EndPoints(a.Dest(), b.Orig())
 	return e
 }
 
 // Swap Essentially truns edge e counterclockwase inside its enclosing
-// quadrilateral. The data pointers are modified accordingly.
+// quadrilateral. The data pointers, the data of the vertices, and the data
+// of the faces if e has a FaceFunc, are modified accordingly.
 func Swap(e *Edge) {
 	a := e.OPrev()
 	b := e.Sym().OPrev()
@@ -53,15 +71,16 @@ func Swap(e *Edge) {
 	Splice(e.Sym(), b)
 	Splice(e, a.LNext())
 	Splice(e.Sym(), b.LNext())
+	e.EndPointsData(a.DestData(), b.DestData())
 	e.EndPoints(a.Dest(), b.Dest())
 }
 
-// Delete will remove the edge from the ring
+// Delete will remove the edge from the ring, leaving it as an edge on it's
+// own. See Pool.Delete to have the edge reused.
 func Delete(e *Edge) {
 	if e == nil {
 		return
 	}
-	log.Printf("Deleting edge %p", e)
 	Splice(e, e.OPrev())
 	Splice(e.Sym(), e.Sym().OPrev())
 }
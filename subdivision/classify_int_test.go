@@ -0,0 +1,77 @@
+// +build int64
+
+package subdivision_test
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/gdey/quad-edge/geometry"
+	"github.com/gdey/quad-edge/subdivision"
+)
+
+func TestClassify(t *testing.T) {
+
+	type tcase struct {
+		a, b, c  geometry.Point
+		expected subdivision.QType
+	}
+	fn := func(tc tcase) func(*testing.T) {
+		return func(t *testing.T) {
+			got := subdivision.Classify(tc.a, tc.b, tc.c)
+			if got != tc.expected {
+				t.Errorf("error, expected %v got %v", tc.expected, got)
+				return
+			}
+		}
+	}
+
+	// The int64 backend truncates coordinates, so the cases use integers.
+	testcases := []tcase{
+		{
+			a:        geometry.NewPoint(11, 25),
+			b:        geometry.NewPoint(10, 20),
+			c:        geometry.NewPoint(10, 30),
+			expected: subdivision.RIGHT,
+		},
+		{
+			a:        geometry.NewPoint(9, 25),
+			b:        geometry.NewPoint(10, 20),
+			c:        geometry.NewPoint(10, 30),
+			expected: subdivision.LEFT,
+		},
+		{
+			a:        geometry.NewPoint(10, 10),
+			b:        geometry.NewPoint(10, 20),
+			c:        geometry.NewPoint(10, 30),
+			expected: subdivision.BEHIND,
+		},
+		{
+			a:        geometry.NewPoint(10, 40),
+			b:        geometry.NewPoint(10, 20),
+			c:        geometry.NewPoint(10, 30),
+			expected: subdivision.BEYOND,
+		},
+		{
+			a:        geometry.NewPoint(10, 20),
+			b:        geometry.NewPoint(10, 20),
+			c:        geometry.NewPoint(10, 30),
+			expected: subdivision.ORIGIN,
+		},
+		{
+			a:        geometry.NewPoint(10, 30),
+			b:        geometry.NewPoint(10, 20),
+			c:        geometry.NewPoint(10, 30),
+			expected: subdivision.DESTINATION,
+		},
+		{
+			a:        geometry.NewPoint(10, 25),
+			b:        geometry.NewPoint(10, 20),
+			c:        geometry.NewPoint(10, 30),
+			expected: subdivision.BETWEEN,
+		},
+	}
+	for i, tc := range testcases {
+		t.Run(strconv.FormatInt(int64(i), 10), fn(tc))
+	}
+}
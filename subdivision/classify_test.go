@@ -1,3 +1,5 @@
+// +build !int64
+
 package subdivision_test
 
 import (
@@ -0,0 +1,83 @@
+package subdivision
+
+import (
+	"context"
+
+	"github.com/gdey/quad-edge/geometry"
+)
+
+// InsertConforming makes each of the segments, made up of vertices of the
+// subdivision, edges of the subdivision by splitting them at their midpoints,
+// which are inserted as sites, until each part is an edge of the Delaunay
+// triangulation. Unlike InsertConstraint the subdivision stays a Delaunay
+// triangulation. The inserted (Steiner) points are returned. The vertexIndex,
+// if not nil, is kept in sync with the subdivision.
+//
+// Segments meeting at small angles may need a lot of points to be recovered,
+// if a segment gets too short to be split ErrSegmentTooShort is returned.
+func (sd *Subdivision) InsertConforming(ctx context.Context, vertexIndex *VertexIndex, segments ...[2]geometry.Point) (steiner []geometry.Point, err error) {
+	if vertexIndex == nil {
+		vertexIndex = sd.VertexIndex()
+	}
+	queue := append([][2]geometry.Point(nil), segments...)
+	for _, s := range queue {
+		for _, pt := range s {
+			if _, ok := vertexIndex.Get(pt); !ok {
+				return nil, ErrVertexNotFound
+			}
+		}
+	}
+
+	var recovered [][2]geometry.Point
+	for len(queue) > 0 {
+		for len(queue) > 0 {
+			if err = ctx.Err(); err != nil {
+				return steiner, err
+			}
+			s := queue[0]
+			queue = queue[1:]
+			if ptEqual(sd.kernel, s[0], &s[1]) {
+				continue
+			}
+			if sd.FindEdge(vertexIndex, s[0], s[1]) != nil {
+				recovered = append(recovered, s)
+				continue
+			}
+
+			se, _ := vertexIndex.Get(s[0])
+			_, through, err := sd.crossingEdges(se, s[0], s[1])
+			if err != nil {
+				return steiner, err
+			}
+			if through != nil {
+				// Split at the vertex on the segment.
+				queue = append(queue, [2]geometry.Point{s[0], *through}, [2]geometry.Point{*through, s[1]})
+				continue
+			}
+
+			a, b := geometry.UnwrapPoint(s[0]), geometry.UnwrapPoint(s[1])
+			mid := geometry.NewPoint((a[0]+b[0])/2, (a[1]+b[1])/2)
+			if ptEqual(sd.kernel, mid, &s[0]) || ptEqual(sd.kernel, mid, &s[1]) {
+				return steiner, ErrSegmentTooShort
+			}
+			if err = sd.insertSite(vertexIndex, mid); err != nil {
+				return steiner, err
+			}
+			steiner = append(steiner, mid)
+			queue = append(queue, [2]geometry.Point{s[0], mid}, [2]geometry.Point{mid, s[1]})
+		}
+
+		// Inserting the midpoints may have swapped away segments that
+		// were already recovered.
+		parts := recovered
+		recovered = nil
+		for _, s := range parts {
+			if sd.FindEdge(vertexIndex, s[0], s[1]) == nil {
+				queue = append(queue, s)
+				continue
+			}
+			recovered = append(recovered, s)
+		}
+	}
+	return steiner, nil
+}
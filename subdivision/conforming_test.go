@@ -0,0 +1,97 @@
+package subdivision
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/gdey/quad-edge/geometry"
+)
+
+// isRecovered returns if the segment is made up of edges of the subdivision.
+func isRecovered(vx *VertexIndex, s [2][2]float64) bool {
+	l := geometry.Line{geometry.NewPoint(s[0][0], s[0][1]), geometry.NewPoint(s[1][0], s[1][1])}
+	dist := func(pt geometry.Point) float64 { return geometry.Magn(geometry.Sub(l[1], pt)) }
+	// Walk from the start, along the edges on the segment, to the end.
+	for cur := l[0]; !geometry.ArePointsEqual(cur, l[1]); {
+		e, ok := vx.Get(cur)
+		if !ok {
+			return false
+		}
+		next := e
+		for !geometry.IsPointOn(l, *next.Dest()) || dist(*next.Dest()) >= dist(cur) {
+			if next = next.ONext(); next == e {
+				return false
+			}
+		}
+		cur = *next.Dest()
+	}
+	return true
+}
+
+func TestInsertConforming(t *testing.T) {
+	type tcase struct {
+		points   [][2]float64
+		segments [][2][2]float64
+	}
+
+	fn := func(tc tcase) func(*testing.T) {
+		return func(t *testing.T) {
+			pts := append([][2]float64(nil), tc.points...)
+			var segments [][2]geometry.Point
+			for _, s := range tc.segments {
+				pts = append(pts, s[0], s[1])
+				segments = append(segments, [2]geometry.Point{
+					geometry.NewPoint(s[0][0], s[0][1]),
+					geometry.NewPoint(s[1][0], s[1][1]),
+				})
+			}
+			sd := insertPoints(t, pts)
+			vx := sd.VertexIndex()
+			steiner, err := sd.InsertConforming(context.Background(), vx, segments...)
+			if err != nil {
+				t.Fatalf("insert conforming, expected nil got %v", err)
+			}
+
+			for _, s := range tc.segments {
+				if !isRecovered(vx, s) {
+					t.Errorf("segment %v, expected to be edges of the subdivision", s)
+				}
+			}
+
+			// The subdivision should be the Delaunay triangulation of all
+			// the points.
+			for _, pt := range steiner {
+				pts = append(pts, geometry.UnwrapPoint(pt))
+			}
+			expected, got := triangleSet(t, insertPoints(t, pts)), triangleSet(t, sd)
+			if len(got) != len(expected) {
+				t.Errorf("number of triangles, expected %v got %v", len(expected), len(got))
+			}
+			for tri := range expected {
+				if !got[tri] {
+					t.Errorf("triangle, expected %v got nothing", tri)
+				}
+			}
+		}
+	}
+
+	tests := []tcase{
+		{
+			points:   [][2]float64{{0, 5}, {5, -5}, {10, 5}, {15, -5}, {20, 5}},
+			segments: [][2][2]float64{{{-5, 0}, {25, 0}}},
+		},
+		{
+			// scaled so the midpoints are integers for the int64 backend
+			points:   [][2]float64{{0, 0}, {640, 640}, {0, 640}, {640, 0}, {320, 768}, {320, -128}, {192, 320}, {448, 320}},
+			segments: [][2][2]float64{{{0, 0}, {640, 640}}, {{0, 640}, {640, 0}}},
+		},
+		{
+			points:   randomPoints(200, 5),
+			segments: [][2][2]float64{{{0, 0}, {1 << 20, 1 << 20}}, {{0, 1 << 20}, {1 << 20, 0}}},
+		},
+	}
+	for i, tc := range tests {
+		t.Run(strconv.Itoa(i), fn(tc))
+	}
+}
@@ -0,0 +1,452 @@
+package subdivision
+
+import (
+	"context"
+	"errors"
+	"math"
+
+	"github.com/gdey/quad-edge/debugger"
+	"github.com/gdey/quad-edge/geometry"
+	"github.com/gdey/quad-edge/quadedge"
+)
+
+// InsertConstraint will insert the edge from start to end in to the
+// subdivision, and mark it as a constraint. Both start and end need to
+// be vertices of the subdivision. The edges crossing the constraint are
+// swapped until the constraint is an edge of the subdivision, (Sloan (1993),
+// "A fast algorithm for generating constrained Delaunay triangulations")
+// the new edges are then swapped until they are Delaunay again, giving a
+// constrained Delaunay triangulation.
+//
+// If the constraint passes through other vertices, it is split at each of
+// them, and each part is inserted and marked as a constraint.
+func (sd *Subdivision) InsertConstraint(ctx context.Context, vertexIndex *VertexIndex, start, end geometry.Point) (err error) {
+
+	if debug {
+
+		ctx = debugger.AugmentContext(ctx, "")
+		defer debugger.Close(ctx)
+
+	}
+
+	if vertexIndex == nil {
+		vertexIndex = sd.VertexIndex()
+	}
+
+	if _, ok := vertexIndex.Get(start); !ok {
+		// start is not in our subdivision
+		return errors.New("Invalid starting vertex.")
+	}
+	if _, ok := vertexIndex.Get(end); !ok {
+		// end is not in our subdivision
+		return errors.New("Invalid end vertex.")
+	}
+
+	_, err = sd.constrain(ctx, vertexIndex, start, end, false)
+	return err
+}
+
+// InsertConstraintSplitting is like InsertConstraint, but instead of failing
+// when the constraint crosses a constraint already in the subdivision, the
+// crossing point is inserted as a site and both constraints are split there.
+// The inserted (Steiner) points are returned. The vertexIndex, if not nil, is
+// kept in sync with the subdivision. If no point close to the crossing is on
+// either of the constraints, ErrIntersectingConstraints is still returned.
+func (sd *Subdivision) InsertConstraintSplitting(ctx context.Context, vertexIndex *VertexIndex, start, end geometry.Point) (steiner []geometry.Point, err error) {
+	if vertexIndex == nil {
+		vertexIndex = sd.VertexIndex()
+	}
+	if _, ok := vertexIndex.Get(start); !ok {
+		return nil, errors.New("Invalid starting vertex.")
+	}
+	if _, ok := vertexIndex.Get(end); !ok {
+		return nil, errors.New("Invalid end vertex.")
+	}
+	return sd.constrain(ctx, vertexIndex, start, end, true)
+}
+
+// constrain inserts the constraint from start to end a part at a time, see
+// insertConstraint.
+func (sd *Subdivision) constrain(ctx context.Context, vertexIndex *VertexIndex, start, end geometry.Point, split bool) (steiner []geometry.Point, err error) {
+	var (
+		next *geometry.Point
+		pts  []geometry.Point
+	)
+	for {
+		if err = ctx.Err(); err != nil {
+			return steiner, err
+		}
+		if ptEqual(sd.kernel, start, &end) {
+			// Nothing left to insert.
+			return steiner, nil
+		}
+		next, pts, err = sd.insertConstraint(ctx, vertexIndex, start, end, split)
+		steiner = append(steiner, pts...)
+		if err != nil || next == nil {
+			return steiner, err
+		}
+		start = *next
+	}
+}
+
+// insertConstraint inserts the constraint from start towards end, up to the
+// first vertex on the segment. If that vertex is not end, it is returned.
+// If split is true, a constraint crossing the segment is split at the
+// crossing point, which becomes the first vertex on the segment, and is
+// returned in steiner if it was not already in the subdivision.
+func (sd *Subdivision) insertConstraint(ctx context.Context, vertexIndex *VertexIndex, start, end geometry.Point, split bool) (next *geometry.Point, steiner []geometry.Point, err error) {
+	startingEdge, _ := vertexIndex.Get(start)
+	if e := startingEdge.FindONextDest(end); e != nil {
+		// Edge already in the subdivision, just need to mark it.
+		e.SetConstrained(true)
+		return nil, nil, nil
+	}
+
+	crossing, through, err := sd.crossingEdges(startingEdge, start, end)
+	if err != nil {
+		return nil, nil, err
+	}
+	if through != nil {
+		// Only insert the constraint up to the vertex.
+		end = *through
+	}
+	for _, e := range crossing {
+		if !e.IsConstrained() {
+			continue
+		}
+		if !split {
+			return nil, nil, ErrIntersectingConstraints
+		}
+		return sd.splitConstraint(ctx, vertexIndex, start, end, e)
+	}
+
+	// Swap the crossing edges, that are the diagonal of a convex
+	// quadrilateral, until none of them cross the constraint.
+	var created []*quadedge.Edge
+	for stalled := 0; len(crossing) > 0; {
+		if stalled > len(crossing) {
+			// Went through all the crossing edges without being able
+			// to swap any of them.
+			return nil, nil, ErrAssumptionFailed()
+		}
+		e := crossing[0]
+		crossing = crossing[1:]
+		if !sd.isConvexQuad(e) {
+			crossing = append(crossing, e)
+			stalled++
+			continue
+		}
+		stalled = 0
+		swap(vertexIndex, e)
+		if sd.crosses(e, start, end) {
+			crossing = append(crossing, e)
+			continue
+		}
+		created = append(created, e)
+	}
+
+	ce, _ := vertexIndex.Get(start)
+	if ce = ce.FindONextDest(end); ce == nil {
+		return nil, nil, ErrConstraintNotInserted
+	}
+	ce.SetConstrained(true)
+	sd.restoreDelaunay(vertexIndex, created...)
+	return through, nil, nil
+}
+
+// splitConstraint inserts the point where the constraint c crosses the segment
+// from start to end, and splits c there. The segment is then inserted from
+// start to the crossing point, which is returned as the next vertex.
+func (sd *Subdivision) splitConstraint(ctx context.Context, vertexIndex *VertexIndex, start, end geometry.Point, c *quadedge.Edge) (next *geometry.Point, steiner []geometry.Point, err error) {
+	a, b := *c.Orig(), *c.Dest()
+	pt, ok := sd.crossingPoint(geometry.Line{start, end}, geometry.Line{a, b})
+	if !ok {
+		// There isn't a point to split at.
+		return nil, nil, ErrIntersectingConstraints
+	}
+	if ptEqual(sd.kernel, start, &pt) || ptEqual(sd.kernel, end, &pt) {
+		// The crossing is too close to the end points to split at.
+		return nil, nil, ErrIntersectingConstraints
+	}
+
+	inserted, err := sd.splitConstraintAt(ctx, vertexIndex, a, b, pt)
+	if inserted {
+		steiner = append(steiner, pt)
+	}
+	if err != nil {
+		return nil, steiner, err
+	}
+
+	pts, err := sd.constrain(ctx, vertexIndex, start, pt, true)
+	steiner = append(steiner, pts...)
+	if err != nil {
+		return nil, steiner, err
+	}
+	return &pt, steiner, nil
+}
+
+// splitConstraintAt inserts pt, a point on, or close to, the constraint from
+// a to b, and replaces the constraint with the constraints from a to pt and
+// from pt to b. It returns if pt was not already in the subdivision.
+func (sd *Subdivision) splitConstraintAt(ctx context.Context, vertexIndex *VertexIndex, a, b, pt geometry.Point) (inserted bool, err error) {
+	if _, ok := vertexIndex.Get(pt); !ok {
+		if err = sd.insertSite(vertexIndex, pt); err != nil {
+			return false, err
+		}
+		inserted = true
+	}
+
+	// If the point did not land exactly on the constraint, it is still
+	// there and needs to be replaced by the two parts.
+	if e := sd.FindEdge(vertexIndex, a, b); e != nil {
+		e.SetConstrained(false)
+	}
+	for _, part := range [2][2]geometry.Point{{a, pt}, {pt, b}} {
+		if ptEqual(sd.kernel, part[0], &part[1]) {
+			// The point is an end point of the constraint.
+			continue
+		}
+		if _, err = sd.constrain(ctx, vertexIndex, part[0], part[1], false); err != nil {
+			return inserted, err
+		}
+	}
+	return inserted, nil
+}
+
+// insertSite is InsertSite, keeping the vertexIndex in sync.
+func (sd *Subdivision) insertSite(vertexIndex *VertexIndex, x geometry.Point) error {
+	if !sd.InsertSite(x) {
+		return ErrAssumptionFailed()
+	}
+	spoke := sd.startingEdge.Sym()
+	if !ptEqual(sd.kernel, x, spoke.Orig()) {
+		// x was already in the subdivision.
+		return nil
+	}
+	// Only the edges around x have changed, the edges of the
+	// vertices around x may have been swapped or deleted.
+	vertexIndex.Set(spoke)
+	for e := spoke; ; {
+		vertexIndex.Set(e.Sym())
+		if e = e.ONext(); e == spoke {
+			break
+		}
+	}
+	return nil
+}
+
+// crossingPoint returns the point where the segment s crosses the
+// constraint c. The computed crossing is rounded, and a point off both of
+// them can be placed in the wrong triangle; so the crossing, and the floats
+// next to it, are checked with the kernel. A point on both is returned, or
+// else one on c, or on s. If none of them are on either false is returned.
+func (sd *Subdivision) crossingPoint(s, c geometry.Line) (pt geometry.Point, ok bool) {
+	x := intersection(
+		geometry.UnwrapPoint(s[0]), geometry.UnwrapPoint(s[1]),
+		geometry.UnwrapPoint(c[0]), geometry.UnwrapPoint(c[1]),
+	)
+	steps := func(f float64) [3]float64 {
+		return [3]float64{f, math.Nextafter(f, math.Inf(-1)), math.Nextafter(f, math.Inf(1))}
+	}
+	best := 0
+	for _, px := range steps(x[0]) {
+		for _, py := range steps(x[1]) {
+			cpt := geometry.NewPoint(px, py)
+			score := 0
+			if sd.kernel.IsPointOn(c, cpt) {
+				score += 2
+			}
+			if sd.kernel.IsPointOn(s, cpt) {
+				score++
+			}
+			if score == 3 {
+				return cpt, true
+			}
+			if score > best {
+				pt, best = cpt, score
+			}
+		}
+	}
+	return pt, best > 0
+}
+
+// intersection returns the point where the line through a and b crosses
+// the line through c and d.
+func intersection(a, b, c, d [2]float64) [2]float64 {
+	r := [2]float64{b[0] - a[0], b[1] - a[1]}
+	s := [2]float64{d[0] - c[0], d[1] - c[1]}
+	t := ((c[0]-a[0])*s[1] - (c[1]-a[1])*s[0]) / (r[0]*s[1] - r[1]*s[0])
+	return [2]float64{a[0] + t*r[0], a[1] + t*r[1]}
+}
+
+// crossingEdges returns the edges that cross the segment from start to end,
+// in order from start. The edge se should have start as it's origin. The
+// edges are directed from the right of the segment to the left of it.
+// If the segment passes through a vertex, only the edges up to that vertex
+// are returned, along with the vertex.
+func (sd *Subdivision) crossingEdges(se *quadedge.Edge, start, end geometry.Point) (crossing []*quadedge.Edge, through *geometry.Point, err error) {
+	var (
+		k       = sd.kernel
+		segment = geometry.Line{start, end}
+		e       *quadedge.Edge
+	)
+
+	// Find the triangle around start that the segment leaves through.
+	for s := se; e == nil; {
+		a, b := *s.Dest(), *s.LNext().Dest()
+		if k.IsPointOn(segment, a) {
+			return nil, s.Dest(), nil
+		}
+		if k.Orientation(start, end, a) < 0 && k.Orientation(start, end, b) > 0 &&
+			k.Orientation(start, a, b) > 0 {
+			e = s.LNext()
+			break
+		}
+		if s = s.ONext(); s == se {
+			return nil, nil, ErrAssumptionFailed()
+		}
+	}
+
+	crossing = append(crossing, e)
+	for i := 0; ; i++ {
+		if i > 3*sd.ptcount {
+			// There can't be more edges then that.
+			return nil, nil, ErrAssumptionFailed()
+		}
+		t := e.Sym()
+		apex := t.LNext().Dest()
+		if k.ArePointsEqual(*apex, end) {
+			return crossing, nil, nil
+		}
+		switch k.Orientation(start, end, *apex) {
+		case 1:
+			e = t.LNext()
+		case -1:
+			e = t.LPrev()
+		default:
+			// The segment passes through the apex.
+			return crossing, apex, nil
+		}
+		crossing = append(crossing, e)
+	}
+}
+
+// crosses returns if the edge e properly crosses the segment from start to end.
+func (sd *Subdivision) crosses(e *quadedge.Edge, start, end geometry.Point) bool {
+	k := sd.kernel
+	o, d := *e.Orig(), *e.Dest()
+	return k.Orientation(start, end, o)*k.Orientation(start, end, d) < 0 &&
+		k.Orientation(o, d, start)*k.Orientation(o, d, end) < 0
+}
+
+// isConvexQuad returns if the quadrilateral made of the two triangles on
+// either side of e is strictly convex, in which case e can be swapped.
+func (sd *Subdivision) isConvexQuad(e *quadedge.Edge) bool {
+	l, r := e.LNext(), e.Sym().LNext()
+	if l.LNext().LNext() != e || r.LNext().LNext() != e.Sym() {
+		// Not between two triangles.
+		return false
+	}
+	return sd.crosses(e, *l.Dest(), *r.Dest())
+}
+
+// swap is quadedge.Swap, keeping the vertexIndex, if not nil, in sync.
+func swap(vertexIndex *VertexIndex, e *quadedge.Edge) {
+	if vertexIndex != nil {
+		vertexIndex.Remove(e)
+	}
+	quadedge.Swap(e)
+	if vertexIndex != nil {
+		vertexIndex.Add(e)
+	}
+}
+
+// RemoveConstraint un-marks the constraint from start to end, and swaps
+// edges around it until the subdivision is Delaunay again. If the constraint
+// passes through other vertices, all of the constrained edges from start to
+// end are un-marked. The edges may be swapped away. If there is no chain of
+// constrained edges from start to end ErrDidNotFindToFrom is returned, and
+// the subdivision is not changed.
+func (sd *Subdivision) RemoveConstraint(vertexIndex *VertexIndex, start, end geometry.Point) error {
+	if vertexIndex == nil {
+		vertexIndex = sd.VertexIndex()
+	}
+	e := sd.FindEdge(vertexIndex, start, end)
+	if e != nil && !e.IsConstrained() {
+		return nil
+	}
+	var chain []*quadedge.Edge
+	if e != nil {
+		chain = append(chain, e)
+	} else {
+		var err error
+		if chain, err = sd.constraintChain(vertexIndex, start, end); err != nil {
+			return err
+		}
+	}
+	for _, e := range chain {
+		e.SetConstrained(false)
+	}
+	sd.restoreDelaunay(vertexIndex, chain...)
+	return nil
+}
+
+// constraintChain returns the constrained edges, in order, that make up
+// the constraint from start to end.
+func (sd *Subdivision) constraintChain(vertexIndex *VertexIndex, start, end geometry.Point) (chain []*quadedge.Edge, err error) {
+	k := sd.kernel
+	for orig := start; !k.ArePointsEqual(orig, end); {
+		se, ok := vertexIndex.Get(orig)
+		if !ok {
+			return nil, ErrDidNotFindToFrom
+		}
+		// The next edge on the constraint is the constrained edge that
+		// goes towards end.
+		segment := geometry.Line{orig, end}
+		var next *quadedge.Edge
+		for e := se; ; {
+			if e.IsConstrained() && k.IsPointOn(segment, *e.Dest()) {
+				next = e
+				break
+			}
+			if e = e.ONext(); e == se {
+				break
+			}
+		}
+		if next == nil {
+			return nil, ErrDidNotFindToFrom
+		}
+		chain = append(chain, next)
+		orig = *next.Dest()
+	}
+	return chain, nil
+}
+
+// restoreDelaunay swaps any edge, starting with the given edges, that is not
+// locally Delaunay; the edges of the swapped quadrilateral are then checked.
+// (Lawson's flip algorithm.) The vertexIndex, if not nil, is kept in sync.
+func (sd *Subdivision) restoreDelaunay(vertexIndex *VertexIndex, edges ...*quadedge.Edge) {
+	var suspect quadedge.Stack
+	for _, e := range edges {
+		suspect.Push(e)
+	}
+	for suspect.Length() > 0 {
+		e := suspect.Pop()
+		if e.IsConstrained() || sd.isHardFrameEdge(e) {
+			continue
+		}
+		l, r := e.LNext(), e.Sym().LNext()
+		if l.LNext().LNext() != e || r.LNext().LNext() != e.Sym() {
+			// Not between two triangles.
+			continue
+		}
+		if !geometry.KernelPerturbedInCircle(sd.kernel, *e.Orig(), *e.Dest(), *l.Dest(), *r.Dest()) {
+			continue
+		}
+		quad := [4]*quadedge.Edge{l, l.LNext(), r, r.LNext()}
+		swap(vertexIndex, e)
+		for _, qe := range quad {
+			suspect.Push(qe)
+		}
+	}
+}
@@ -0,0 +1,308 @@
+package subdivision
+
+import (
+	"context"
+	"math"
+	"strconv"
+	"testing"
+
+	"github.com/gdey/quad-edge/geometry"
+	"github.com/gdey/quad-edge/quadedge"
+)
+
+// constrainedLength returns the sum of the lengths of the constrained edges,
+// and if all of them are on the segment.
+func constrainedLength(sd *Subdivision, segment [2][2]float64) (length float64, on bool) {
+	on = true
+	l := geometry.Line{
+		geometry.NewPoint(segment[0][0], segment[0][1]),
+		geometry.NewPoint(segment[1][0], segment[1][1]),
+	}
+	_ = sd.WalkAllEdges(func(e *quadedge.Edge) error {
+		if !e.IsConstrained() {
+			return nil
+		}
+		if !geometry.IsPointOn(l, *e.Orig()) || !geometry.IsPointOn(l, *e.Dest()) {
+			on = false
+		}
+		o, d := geometry.UnwrapPoint(*e.Orig()), geometry.UnwrapPoint(*e.Dest())
+		length += math.Hypot(d[0]-o[0], d[1]-o[1])
+		return nil
+	})
+	return length, on
+}
+
+func TestInsertConstraint(t *testing.T) {
+	type tcase struct {
+		points     [][2]float64
+		constraint [2][2]float64
+		// sites to insert after the constraint
+		sites [][2]float64
+	}
+
+	fn := func(tc tcase) func(*testing.T) {
+		return func(t *testing.T) {
+			ctx := context.Background()
+			pts := append(append([][2]float64(nil), tc.points...), tc.sites...)
+			pts = append(pts, tc.constraint[0], tc.constraint[1])
+			tri := geometry.TriangleContaining(pts...)
+			sd := New(
+				geometry.NewPoint(tri[0][0], tri[0][1]),
+				geometry.NewPoint(tri[1][0], tri[1][1]),
+				geometry.NewPoint(tri[2][0], tri[2][1]),
+			)
+			for _, pt := range append(tc.points, tc.constraint[0], tc.constraint[1]) {
+				if !sd.InsertSite(geometry.NewPoint(pt[0], pt[1])) {
+					t.Fatalf("failed to insert point %v", pt)
+				}
+			}
+
+			start := geometry.NewPoint(tc.constraint[0][0], tc.constraint[0][1])
+			end := geometry.NewPoint(tc.constraint[1][0], tc.constraint[1][1])
+			if err := sd.InsertConstraint(ctx, nil, start, end); err != nil {
+				t.Fatalf("insert constraint, expected nil got %v", err)
+			}
+			e := sd.FindEdge(nil, start, end)
+			if e == nil || !e.IsConstrained() {
+				t.Fatalf("constraint edge, expected constrained edge got %v", e)
+			}
+
+			for _, pt := range tc.sites {
+				if !sd.InsertSite(geometry.NewPoint(pt[0], pt[1])) {
+					t.Fatalf("failed to insert point %v", pt)
+				}
+			}
+			expectedLength := math.Hypot(tc.constraint[1][0]-tc.constraint[0][0], tc.constraint[1][1]-tc.constraint[0][1])
+			length, on := constrainedLength(sd, tc.constraint)
+			if !on {
+				t.Errorf("constrained edges, expected all on the constraint")
+			}
+			if math.Abs(length-expectedLength) > 1e-9*expectedLength {
+				t.Errorf("constrained length, expected %v got %v", expectedLength, length)
+			}
+
+			// Removing the constraint, the subdivision should be the
+			// Delaunay triangulation again. Sites on the constraint stay.
+			vx := sd.VertexIndex()
+			_ = sd.WalkAllEdges(func(e *quadedge.Edge) error {
+				if e.IsConstrained() {
+					if err := sd.RemoveConstraint(vx, *e.Orig(), *e.Dest()); err != nil {
+						t.Errorf("remove constraint, expected nil got %v", err)
+					}
+				}
+				return nil
+			})
+			dsd := New(
+				geometry.NewPoint(tri[0][0], tri[0][1]),
+				geometry.NewPoint(tri[1][0], tri[1][1]),
+				geometry.NewPoint(tri[2][0], tri[2][1]),
+			)
+			for _, pt := range pts {
+				dsd.InsertSite(geometry.NewPoint(pt[0], pt[1]))
+			}
+			expected, got := triangleSet(t, dsd), triangleSet(t, sd)
+			if len(got) != len(expected) {
+				t.Errorf("number of triangles, expected %v got %v", len(expected), len(got))
+			}
+			for tri := range expected {
+				if !got[tri] {
+					t.Errorf("triangle, expected %v got nothing", tri)
+				}
+			}
+		}
+	}
+
+	random := randomPoints(200, 3)
+	tests := []tcase{
+		{
+			points:     [][2]float64{{516, 661}, {369, 793}, {426, 539}, {273, 525}, {747, 750}, {454, 390}},
+			constraint: [2][2]float64{{204, 694}, {516, 661}},
+		},
+		{
+			points:     [][2]float64{{0, 5}, {5, -5}, {10, 5}, {15, -5}, {20, 5}},
+			constraint: [2][2]float64{{-5, 0}, {25, 0}},
+			// points on, and either side of, the constraint
+			sites: [][2]float64{{10, 0}, {3, 1}, {7, -1}, {20, 0}, {12, 0.5}},
+		},
+		{
+			points:     random,
+			constraint: [2][2]float64{{0, 0}, {1 << 20, 1 << 20}},
+			sites:      randomPoints(200, 4),
+		},
+	}
+	for i, tc := range tests {
+		t.Run(strconv.Itoa(i), fn(tc))
+	}
+}
+
+func TestInsertConstraintIntersecting(t *testing.T) {
+	ctx := context.Background()
+	sd := insertPoints(t, [][2]float64{{0, 0}, {10, 10}, {0, 10}, {10, 0}, {5, 12}, {5, -2}})
+	if err := sd.InsertConstraint(ctx, nil, geometry.NewPoint(0, 0), geometry.NewPoint(10, 10)); err != nil {
+		t.Fatalf("insert constraint, expected nil got %v", err)
+	}
+	err := sd.InsertConstraint(ctx, nil, geometry.NewPoint(0, 10), geometry.NewPoint(10, 0))
+	if err != ErrIntersectingConstraints {
+		t.Errorf("error, expected %v got %v", ErrIntersectingConstraints, err)
+	}
+	if e := sd.FindEdge(nil, geometry.NewPoint(0, 0), geometry.NewPoint(10, 10)); e == nil || !e.IsConstrained() {
+		t.Errorf("constraint edge, expected constrained edge got %v", e)
+	}
+}
+
+func TestInsertConstraintCollinear(t *testing.T) {
+	ctx := context.Background()
+	// (5,5), (10,10) and (15,15) are on the constraint.
+	sd := insertPoints(t, [][2]float64{{0, 0}, {20, 20}, {5, 5}, {10, 10}, {15, 15}, {0, 20}, {20, 0}, {12, 8}, {8, 12}})
+	start, end := geometry.NewPoint(0, 0), geometry.NewPoint(20, 20)
+	if err := sd.InsertConstraint(ctx, nil, start, end); err != nil {
+		t.Fatalf("insert constraint, expected nil got %v", err)
+	}
+	parts := [][2][2]float64{{{0, 0}, {5, 5}}, {{5, 5}, {10, 10}}, {{10, 10}, {15, 15}}, {{15, 15}, {20, 20}}}
+	for _, p := range parts {
+		e := sd.FindEdge(nil, geometry.NewPoint(p[0][0], p[0][1]), geometry.NewPoint(p[1][0], p[1][1]))
+		if e == nil || !e.IsConstrained() {
+			t.Errorf("constraint edge %v, expected constrained edge got %v", p, e)
+		}
+	}
+	length, on := constrainedLength(sd, [2][2]float64{{0, 0}, {20, 20}})
+	if !on {
+		t.Errorf("constrained edges, expected all on the constraint")
+	}
+	if expected := 20 * math.Sqrt2; math.Abs(length-expected) > 1e-9 {
+		t.Errorf("constrained length, expected %v got %v", expected, length)
+	}
+}
+
+func TestInsertConstraintSplitting(t *testing.T) {
+	ctx := context.Background()
+	sd := insertPoints(t, [][2]float64{
+		{0, 0}, {10, 10}, {0, 10}, {10, 0}, {5, 12}, {5, -2},
+		{-2, 4}, {12, 4}, {2, 8}, {7, 3},
+	})
+	vx := sd.VertexIndex()
+	constraints := [][2][2]float64{
+		{{0, 0}, {10, 10}},
+		{{0, 10}, {10, 0}},
+		// crosses both of the above, away from their crossing
+		{{-2, 4}, {12, 4}},
+	}
+	var steiner []geometry.Point
+	for _, c := range constraints {
+		pts, err := sd.InsertConstraintSplitting(ctx, vx,
+			geometry.NewPoint(c[0][0], c[0][1]), geometry.NewPoint(c[1][0], c[1][1]),
+		)
+		if err != nil {
+			t.Fatalf("insert constraint %v, expected nil got %v", c, err)
+		}
+		steiner = append(steiner, pts...)
+	}
+
+	expected := [][2]float64{{5, 5}, {4, 4}, {6, 4}}
+	if len(steiner) != len(expected) {
+		t.Fatalf("steiner points, expected %v got %v", expected, steiner)
+	}
+	for i := range expected {
+		if got := geometry.UnwrapPoint(steiner[i]); got != expected[i] {
+			t.Errorf("steiner point %v, expected %v got %v", i, expected[i], got)
+		}
+	}
+
+	for _, c := range constraints {
+		expectedLength := math.Hypot(c[1][0]-c[0][0], c[1][1]-c[0][1])
+		var length float64
+		_ = sd.WalkAllEdges(func(e *quadedge.Edge) error {
+			l := geometry.Line{geometry.NewPoint(c[0][0], c[0][1]), geometry.NewPoint(c[1][0], c[1][1])}
+			if e.IsConstrained() && geometry.IsPointOn(l, *e.Orig()) && geometry.IsPointOn(l, *e.Dest()) {
+				o, d := geometry.UnwrapPoint(*e.Orig()), geometry.UnwrapPoint(*e.Dest())
+				length += math.Hypot(d[0]-o[0], d[1]-o[1])
+			}
+			return nil
+		})
+		if math.Abs(length-expectedLength) > 1e-9*expectedLength {
+			t.Errorf("constraint %v length, expected %v got %v", c, expectedLength, length)
+		}
+	}
+
+	// The vertex index should still be valid.
+	vx.Each(func(e *quadedge.Edge) {
+		if got, _ := vx.Get(*e.Orig()); got != e {
+			t.Errorf("vertex index %v, expected edge from it got %v", *e.Orig(), e.AsGeomLine())
+		}
+	})
+}
+
+func TestRemoveConstraintCollinear(t *testing.T) {
+	ctx := context.Background()
+	pts := [][2]float64{{0, 0}, {20, 20}, {10, 10}, {0, 20}, {20, 0}, {12, 8}, {8, 12}}
+	sd := insertPoints(t, pts)
+	expected := triangleSet(t, sd)
+	start, end := geometry.NewPoint(0, 0), geometry.NewPoint(20, 20)
+	if err := sd.InsertConstraint(ctx, nil, start, end); err != nil {
+		t.Fatalf("insert constraint, expected nil got %v", err)
+	}
+	if err := sd.RemoveConstraint(nil, geometry.NewPoint(0, 20), geometry.NewPoint(20, 0)); err != ErrDidNotFindToFrom {
+		t.Errorf("remove missing constraint, expected %v got %v", ErrDidNotFindToFrom, err)
+	}
+	if length, _ := constrainedLength(sd, [2][2]float64{{0, 0}, {20, 20}}); length == 0 {
+		t.Fatalf("constrained length, expected the constraint got nothing")
+	}
+
+	// The constraint is made up of (0,0)→(10,10) and (10,10)→(20,20).
+	if err := sd.RemoveConstraint(nil, start, end); err != nil {
+		t.Fatalf("remove constraint, expected nil got %v", err)
+	}
+	if length, _ := constrainedLength(sd, [2][2]float64{{0, 0}, {20, 20}}); length != 0 {
+		t.Errorf("constrained length, expected 0 got %v", length)
+	}
+	got := triangleSet(t, sd)
+	if len(got) != len(expected) {
+		t.Errorf("number of triangles, expected %v got %v", len(expected), len(got))
+	}
+	for tri := range expected {
+		if !got[tri] {
+			t.Errorf("triangle, expected %v got nothing", tri)
+		}
+	}
+}
+
+func TestCrossingPoint(t *testing.T) {
+	if geometry.Type == "int64" {
+		// The crossings are truncated to integers.
+		t.Skip("the cases need a floating point backend")
+	}
+	type tcase struct {
+		segment, constraint [2][2]float64
+		onSegment, ok       bool
+	}
+	sd := insertPoints(t, [][2]float64{{0, 0}, {1, 1}})
+	fn := func(tc tcase) func(*testing.T) {
+		return func(t *testing.T) {
+			s := geometry.Line{geometry.NewPoint(tc.segment[0][0], tc.segment[0][1]), geometry.NewPoint(tc.segment[1][0], tc.segment[1][1])}
+			c := geometry.Line{geometry.NewPoint(tc.constraint[0][0], tc.constraint[0][1]), geometry.NewPoint(tc.constraint[1][0], tc.constraint[1][1])}
+			pt, ok := sd.crossingPoint(s, c)
+			if ok != tc.ok {
+				t.Fatalf("ok, expected %v got %v", tc.ok, ok)
+			}
+			if !ok {
+				return
+			}
+			if !sd.kernel.IsPointOn(c, pt) {
+				t.Errorf("point %v, expected on the constraint", geometry.UnwrapPoint(pt))
+			}
+			if on := sd.kernel.IsPointOn(s, pt); on != tc.onSegment {
+				t.Errorf("point %v on the segment, expected %v got %v", geometry.UnwrapPoint(pt), tc.onSegment, on)
+			}
+		}
+	}
+	tests := []tcase{
+		{segment: [2][2]float64{{0, 0}, {10, 10}}, constraint: [2][2]float64{{0, 10}, {10, 0}}, onSegment: true, ok: true},
+		// The crossing is rounded off of the segment.
+		{segment: [2][2]float64{{0, 0}, {3, 7}}, constraint: [2][2]float64{{0, 5}, {11, 1}}, ok: true},
+		// None of the points around the crossing are on either.
+		{segment: [2][2]float64{{0.1, 0.3}, {3.7, 7.1}}, constraint: [2][2]float64{{0, 5.3}, {11.9, 1.3}}},
+	}
+	for i, tc := range tests {
+		t.Run(strconv.Itoa(i), fn(tc))
+	}
+}
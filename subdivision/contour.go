@@ -0,0 +1,126 @@
+package subdivision
+
+import (
+	"github.com/gdey/quad-edge/geometry"
+	"github.com/gdey/quad-edge/quadedge"
+	"github.com/go-spatial/geom"
+)
+
+// Contours returns the contour lines, at each of the levels, of the surface
+// of the TIN; a MultiLineString for each level. The segments of the lines in
+// each triangle are joined, across the edges the triangles share, in to lines
+// that either end on the hull or are closed rings. The lines are all in the
+// same direction, the higher values are on the same side of all of them.
+//
+// A vertex at the level is taken to be above it, so a contour only touches a
+// vertex, it does not go along edges.
+func (tin *TIN) Contours(levels []float64) ([]geom.MultiLineString, error) {
+	// The faces, by the edges with the face on the left, that are
+	// triangles not attached to the frame.
+	faces := make(map[*quadedge.Edge]bool)
+	err := WalkAllTriangleEdges(tin.startingEdge, func(edges []*quadedge.Edge) error {
+		if len(edges) != 3 || tin.isFramePoint(*edges[0].Orig(), *edges[1].Orig(), *edges[2].Orig()) {
+			return nil
+		}
+		for _, e := range edges {
+			if _, err := vertexZ(e); err != nil {
+				return err
+			}
+		}
+		for _, e := range edges {
+			faces[e] = true
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	contours := make([]geom.MultiLineString, len(levels))
+	for i, level := range levels {
+		contours[i] = tin.contour(faces, level)
+	}
+	return contours, nil
+}
+
+// contour returns the contour lines at the level, through the faces.
+func (tin *TIN) contour(faces map[*quadedge.Edge]bool, level float64) (lines geom.MultiLineString) {
+	// above returns if the origin of e is above the level.
+	above := func(e *quadedge.Edge) bool {
+		z, _ := vertexZ(e)
+		return z >= level
+	}
+	// entry returns if the contour enters the face on the left of e
+	// through e; crossing it from above to below.
+	entry := func(e *quadedge.Edge) bool {
+		return faces[e] && above(e) && !above(e.Sym())
+	}
+	// exit returns if the contour leaves the face on the left of e through
+	// e; crossing it from below to above.
+	exit := func(e *quadedge.Edge) bool {
+		return faces[e] && !above(e) && above(e.Sym())
+	}
+
+	// The crossing points, by QuadEdge, so they are the same from either
+	// side of the edge.
+	crossings := make(map[*quadedge.QuadEdge][2]float64)
+	crossing := func(e *quadedge.Edge) [2]float64 {
+		if pt, ok := crossings[e.QEdge()]; ok {
+			return pt
+		}
+		za, _ := vertexZ(e)
+		zb, _ := vertexZ(e.Sym())
+		a, b := geometry.UnwrapPoint(*e.Orig()), geometry.UnwrapPoint(*e.Dest())
+		t := (level - za) / (zb - za)
+		pt := [2]float64{a[0] + t*(b[0]-a[0]), a[1] + t*(b[1]-a[1])}
+		crossings[e.QEdge()] = pt
+		return pt
+	}
+
+	visited := make(map[*quadedge.Edge]bool)
+	// follow returns the line entering the face on the left of e, up to
+	// where it leaves the faces or gets back to e.
+	follow := func(e *quadedge.Edge) (line [][2]float64) {
+		start := e
+		line = append(line, crossing(e))
+		for {
+			visited[e] = true
+			x := e.LNext()
+			if !exit(x) {
+				x = x.LNext()
+			}
+			if pt := crossing(x); pt != line[len(line)-1] {
+				line = append(line, pt)
+			}
+			if e = x.Sym(); e == start || !faces[e] {
+				return line
+			}
+		}
+	}
+
+	var entries []*quadedge.Edge
+	_ = tin.WalkAllEdges(func(e *quadedge.Edge) error {
+		for _, e := range [2]*quadedge.Edge{e, e.Sym()} {
+			if entry(e) {
+				entries = append(entries, e)
+			}
+		}
+		return nil
+	})
+	// Lines that start on the hull, then the rings.
+	for _, e := range entries {
+		if !faces[e.Sym()] {
+			if line := follow(e); len(line) > 1 {
+				lines = append(lines, line)
+			}
+		}
+	}
+	for _, e := range entries {
+		if !visited[e] {
+			if line := follow(e); len(line) > 1 {
+				lines = append(lines, line)
+			}
+		}
+	}
+	return lines
+}
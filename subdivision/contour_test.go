@@ -0,0 +1,77 @@
+package subdivision
+
+import (
+	"context"
+	"math"
+	"testing"
+)
+
+// gridTIN returns a TIN with the points of a size by size grid, and z
+// given by fn.
+func gridTIN(t *testing.T, size int, fn func(x, y float64) float64) *TIN {
+	var pts [][3]float64
+	for x := 0; x <= size; x++ {
+		for y := 0; y <= size; y++ {
+			pts = append(pts, [3]float64{float64(x), float64(y), fn(float64(x), float64(y))})
+		}
+	}
+	tin, err := NewTIN(context.Background(), pts)
+	if err != nil {
+		t.Fatalf("new tin, expected nil got %v", err)
+	}
+	return tin
+}
+
+func TestContoursPlane(t *testing.T) {
+	tin := gridTIN(t, 10, func(x, y float64) float64 { return x })
+	contours, err := tin.Contours([]float64{4.5, 20})
+	if err != nil {
+		t.Fatalf("contours, expected nil got %v", err)
+	}
+	if len(contours) != 2 {
+		t.Fatalf("number of contours, expected 2 got %v", len(contours))
+	}
+	if len(contours[1]) != 0 {
+		t.Errorf("contour above the surface, expected no lines got %v", contours[1])
+	}
+
+	if len(contours[0]) != 1 {
+		t.Fatalf("lines, expected 1 got %v", contours[0])
+	}
+	line := contours[0][0]
+	for _, pt := range line {
+		if pt[0] != 4.5 {
+			t.Errorf("point %v, expected on x = 4.5", pt)
+		}
+	}
+	// The line goes from one side of the hull to the other.
+	ys := []float64{line[0][1], line[len(line)-1][1]}
+	if math.Min(ys[0], ys[1]) != 0 || math.Max(ys[0], ys[1]) != 10 {
+		t.Errorf("end points, expected on the hull got %v %v", line[0], line[len(line)-1])
+	}
+}
+
+func TestContoursCone(t *testing.T) {
+	tin := gridTIN(t, 20, func(x, y float64) float64 { return math.Hypot(x-10, y-10) })
+	contours, err := tin.Contours([]float64{5})
+	if err != nil {
+		t.Fatalf("contours, expected nil got %v", err)
+	}
+	if len(contours[0]) != 1 {
+		t.Fatalf("lines, expected 1 got %v", contours[0])
+	}
+	ring := contours[0][0]
+	if len(ring) < 4 || ring[0] != ring[len(ring)-1] {
+		t.Fatalf("line, expected closed ring got %v", ring)
+	}
+	for _, pt := range ring {
+		// The interpolated surface is above the cone.
+		if d := math.Hypot(pt[0]-10, pt[1]-10); d < 4.5 || d > 5+1e-9 {
+			t.Errorf("point %v, expected about 5 from the center got %v", pt, d)
+		}
+	}
+	// The ring goes around the peak.
+	if area := ringArea(ring); math.Abs(area) < 3*5*5 {
+		t.Errorf("ring area, expected about %v got %v", math.Pi*5*5, area)
+	}
+}
@@ -0,0 +1,223 @@
+package subdivision
+
+import (
+	"context"
+	"runtime"
+	"sort"
+
+	"github.com/gdey/quad-edge/geometry"
+	"github.com/gdey/quad-edge/quadedge"
+	"github.com/go-spatial/geom/cmp"
+)
+
+// NewForPointsDC returns the same subdivision as NewForPoints, built with the
+// divide and conquer algorithm of Guibas and Stolfi (1985) p.114, instead of
+// inserting the points one at a time. The points are split in half, by x,
+// and the halves are triangulated concurrently, in up to workers goroutines,
+// then merged. If workers is less than one, runtime.NumCPU is used.
+//
+// The frame vertices are triangulated with the points; the Delaunay
+// triangulation of the points and the frame is what inserting the points in
+// to the frame gives. Ties, for cocircular points, are broken the same way
+// as InsertSite; see geometry.KernelPerturbedInCircle.
+//
+// If the context is canceled nil is returned.
+func NewForPointsDC(ctx context.Context, points [][2]float64, workers int, opts ...Option) *Subdivision {
+	if workers < 1 {
+		workers = runtime.NumCPU()
+	}
+	sort.Sort(cmp.ByXY(points))
+	tri := geometry.TriangleContaining(points...)
+	sd := New(
+		geometry.NewPoint(tri[0][0], tri[0][1]),
+		geometry.NewPoint(tri[1][0], tri[1][1]),
+		geometry.NewPoint(tri[2][0], tri[2][1]),
+		opts...,
+	)
+
+	// The points are compared by their keys, so points the kernel takes as
+	// equal are the same point. The points are sorted, and so are their keys
+	// unless the kernel has it's own; then only the frame needs to be merged
+	// in to them.
+	key := func(pt geometry.Point) [2]float64 { return geometry.PointKey(sd.kernel, pt) }
+	less := func(a, b geometry.Point) bool { return cmp.PointLess(key(a), key(b)) }
+	sorted := make([]geometry.Point, len(points))
+	for i, pt := range points {
+		sorted[i] = geometry.NewPoint(pt[0], pt[1])
+	}
+	if _, ok := sd.kernel.(geometry.PointKeyer); ok {
+		sort.SliceStable(sorted, func(i, j int) bool { return less(sorted[i], sorted[j]) })
+	}
+	frame := sd.frame
+	sort.Slice(frame[:], func(i, j int) bool { return less(frame[i], frame[j]) })
+	pts := make([]geometry.Point, 0, len(points)+3)
+	f := 0
+	for i, pt := range sorted {
+		if i != 0 && key(sorted[i-1]) == key(pt) {
+			continue
+		}
+		for ; f < len(frame) && less(frame[f], pt); f++ {
+			pts = append(pts, frame[f])
+		}
+		pts = append(pts, pt)
+	}
+	pts = append(pts, frame[f:]...)
+
+	// The number of levels of the recursion that are run concurrently.
+	depth := 0
+	for n := 1; n < workers; n *= 2 {
+		depth++
+	}
+	// The frame edges from New are replaced by the triangulation.
+	frameEdge := sd.startingEdge
+	for _, e := range [3]*quadedge.Edge{frameEdge, frameEdge.LNext(), frameEdge.LPrev()} {
+		sd.pool.Delete(e)
+	}
+
+	dc := divideConquer{ctx: ctx, kernel: sd.kernel}
+	le, _ := dc.triangulate(sd.pool, pts, depth)
+	if ctx.Err() != nil {
+		return nil
+	}
+
+	sd.startingEdge = le
+	sd.ptcount = len(pts)
+	// The options are applied again, for the new edges.
+	for _, opt := range opts {
+		opt(sd)
+	}
+	sd.updateGrid()
+	return sd
+}
+
+// divideConquer holds what is shared by the recursive calls of the
+// divide and conquer triangulation.
+type divideConquer struct {
+	ctx    context.Context
+	kernel geometry.Kernel
+}
+
+func (dc divideConquer) ccw(a, b, c geometry.Point) bool {
+	return dc.kernel.Orientation(a, b, c) > 0
+}
+
+func (dc divideConquer) rightOf(x geometry.Point, e *quadedge.Edge) bool {
+	return dc.ccw(x, *e.Dest(), *e.Orig())
+}
+
+func (dc divideConquer) leftOf(x geometry.Point, e *quadedge.Edge) bool {
+	return dc.ccw(x, *e.Orig(), *e.Dest())
+}
+
+// inCircle returns if d is inside of the circle through a, b, c; which are
+// counterclockwise.
+func (dc divideConquer) inCircle(a, b, c, d *geometry.Point) bool {
+	return geometry.KernelPerturbedInCircle(dc.kernel, *a, *b, *c, *d)
+}
+
+// triangulate returns the counterclockwise convex hull edge out of the left
+// most point, and the clockwise convex hull edge out of the right most point,
+// of the Delaunay triangulation of the points; which need to be sorted by x
+// then y, with no duplicates. The halves are triangulated concurrently for
+// depth levels; each goroutine with it's own pool, which are merged in to
+// pool.
+func (dc divideConquer) triangulate(pool *quadedge.Pool, pts []geometry.Point, depth int) (le, re *quadedge.Edge) {
+	if dc.ctx.Err() != nil {
+		return nil, nil
+	}
+	switch len(pts) {
+	case 0, 1:
+		return nil, nil
+	case 2:
+		a := pool.NewWithEndPoints(&pts[0], &pts[1])
+		return a, a.Sym()
+	case 3:
+		a := pool.NewWithEndPoints(&pts[0], &pts[1])
+		b := pool.NewWithEndPoints(&pts[1], &pts[2])
+		quadedge.Splice(a.Sym(), b)
+		switch {
+		case dc.ccw(pts[0], pts[1], pts[2]):
+			pool.Connect(b, a)
+			return a, b.Sym()
+		case dc.ccw(pts[0], pts[2], pts[1]):
+			c := pool.Connect(b, a)
+			return c.Sym(), c
+		default:
+			// The points are colinear.
+			return a, b.Sym()
+		}
+	}
+
+	half := len(pts) / 2
+	var ldo, ldi, rdi, rdo *quadedge.Edge
+	if depth > 0 {
+		rpool := quadedge.NewPool()
+		done := make(chan struct{})
+		go func() {
+			rdi, rdo = dc.triangulate(rpool, pts[half:], depth-1)
+			close(done)
+		}()
+		ldo, ldi = dc.triangulate(pool, pts[:half], depth-1)
+		<-done
+		pool.Merge(rpool)
+	} else {
+		ldo, ldi = dc.triangulate(pool, pts[:half], 0)
+		rdi, rdo = dc.triangulate(pool, pts[half:], 0)
+	}
+	if dc.ctx.Err() != nil {
+		return nil, nil
+	}
+
+	// Find the lower common tangent of the two halves.
+	for {
+		if dc.leftOf(*rdi.Orig(), ldi) {
+			ldi = ldi.LNext()
+		} else if dc.rightOf(*ldi.Orig(), rdi) {
+			rdi = rdi.RPrev()
+		} else {
+			break
+		}
+	}
+
+	basel := pool.Connect(rdi.Sym(), ldi)
+	if ldi.Orig() == ldo.Orig() {
+		ldo = basel.Sym()
+	}
+	if rdi.Orig() == rdo.Orig() {
+		rdo = basel
+	}
+
+	valid := func(e *quadedge.Edge) bool {
+		return dc.rightOf(*e.Dest(), basel)
+	}
+	// Merge the halves, from the bottom up.
+	for {
+		lcand := basel.Sym().ONext()
+		if valid(lcand) {
+			for dc.inCircle(basel.Dest(), basel.Orig(), lcand.Dest(), lcand.ONext().Dest()) {
+				t := lcand.ONext()
+				pool.Delete(lcand)
+				lcand = t
+			}
+		}
+		rcand := basel.OPrev()
+		if valid(rcand) {
+			for dc.inCircle(basel.Dest(), basel.Orig(), rcand.Dest(), rcand.OPrev().Dest()) {
+				t := rcand.OPrev()
+				pool.Delete(rcand)
+				rcand = t
+			}
+		}
+		lvalid, rvalid := valid(lcand), valid(rcand)
+		if !lvalid && !rvalid {
+			// basel is the upper common tangent.
+			break
+		}
+		if !lvalid || (rvalid && dc.inCircle(lcand.Dest(), lcand.Orig(), rcand.Orig(), rcand.Dest())) {
+			basel = pool.Connect(rcand, basel.Sym())
+		} else {
+			basel = pool.Connect(basel.Sym(), lcand.Sym())
+		}
+	}
+	return ldo, rdo
+}
@@ -0,0 +1,101 @@
+package subdivision
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gdey/quad-edge/geometry"
+	"github.com/gdey/quad-edge/quadedge"
+)
+
+func TestNewForPointsDC(t *testing.T) {
+	type tcase struct {
+		points  [][2]float64
+		workers int
+		opts    []Option
+	}
+	grid := func(size int) (pts [][2]float64) {
+		for x := 0; x <= size; x++ {
+			for y := 0; y <= size; y++ {
+				pts = append(pts, [2]float64{float64(x * 8), float64(y * 8)})
+			}
+		}
+		return pts
+	}
+	triangleSet := func(t *testing.T, sd *Subdivision) map[[3][2]float64]bool {
+		tris, err := sd.Triangles(true)
+		if err != nil {
+			t.Fatalf("triangles, expected nil got %v", err)
+		}
+		set := make(map[[3][2]float64]bool)
+		for _, tri := range tris {
+			// The kernel may take points with different coordinates
+			// as the same.
+			for i := range tri {
+				k := geometry.PointKey(sd.kernel, tri[i])
+				tri[i] = geometry.NewPoint(k[0], k[1])
+			}
+			set[triangleKey(tri)] = true
+		}
+		return set
+	}
+	fn := func(tc tcase) func(*testing.T) {
+		return func(t *testing.T) {
+			ctx := context.Background()
+			expected := triangleSet(t, NewForPoints(ctx, append([][2]float64(nil), tc.points...), tc.opts...))
+			sd := NewForPointsDC(ctx, append([][2]float64(nil), tc.points...), tc.workers, tc.opts...)
+			if !sd.IsValid(ctx) {
+				t.Errorf("is valid, expected true got false")
+			}
+			got := triangleSet(t, sd)
+			if len(got) != len(expected) {
+				t.Errorf("number of triangles, expected %v got %v", len(expected), len(got))
+			}
+			for key := range expected {
+				if !got[key] {
+					t.Errorf("triangle %v, expected to be in the triangulation", key)
+				}
+			}
+			// All of the edges are from the pool of the subdivision.
+			var count int
+			_ = sd.WalkAllEdges(func(*quadedge.Edge) error {
+				count++
+				return nil
+			})
+			if live, _ := sd.EdgeCounts(); live != count {
+				t.Errorf("live edges, expected %v got %v", count, live)
+			}
+			// The subdivision can still be added to.
+			if !sd.InsertSite(geometry.NewPoint(3, 5)) {
+				t.Errorf("insert site, expected true got false")
+			}
+		}
+	}
+	tests := map[string]tcase{
+		"random":          {points: randomPoints(5000, 5), workers: 4},
+		"random one":      {points: randomPoints(500, 6), workers: 1},
+		"random odd":      {points: randomPoints(777, 7), workers: 3},
+		"grid":            {points: grid(20), workers: 8},
+		"line":            {points: [][2]float64{{0, 0}, {10, 10}, {20, 20}, {30, 30}}, workers: 2},
+		"repeats":         {points: [][2]float64{{0, 0}, {0, 0}, {10, 0}, {10, 0}, {5, 10}}, workers: 2},
+		"default workers": {points: randomPoints(300, 8)},
+		// (0.1,1) and (0.3,1) are the same integer point, but are not
+		// next to each other when sorted.
+		"int kernel": {
+			points:  [][2]float64{{0.1, 1}, {0.2, 5}, {0.3, 1}, {4, 4}, {7, 1}, {3, 8}, {6, 6}},
+			workers: 2,
+			opts:    []Option{WithKernel(geometry.IntKernel{})},
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, fn(tc))
+	}
+}
+
+func TestNewForPointsDCCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if sd := NewForPointsDC(ctx, randomPoints(100, 1), 2); sd != nil {
+		t.Errorf("canceled, expected nil got a subdivision")
+	}
+}
@@ -0,0 +1,166 @@
+package subdivision
+
+import (
+	"github.com/gdey/quad-edge/geometry"
+	"github.com/gdey/quad-edge/quadedge"
+)
+
+// faceEdges returns the edges of the face on the left of e.
+func faceEdges(e *quadedge.Edge) (edges []*quadedge.Edge) {
+	curr := e
+	for {
+		edges = append(edges, curr)
+		if curr = curr.LNext(); curr == e {
+			return edges
+		}
+	}
+}
+
+// constraintDepths returns, for each edge, the smallest number of constraints
+// that need to be crossed to get from the face on the left of start to the
+// face on the left of the edge.
+func constraintDepths(start *quadedge.Edge) map[*quadedge.Edge]int {
+	depths := make(map[*quadedge.Edge]int)
+	// Faces at the current depth, and at the next depth.
+	current, next := []*quadedge.Edge{start}, []*quadedge.Edge(nil)
+	for depth := 0; len(current) > 0; depth++ {
+		for len(current) > 0 {
+			e := current[len(current)-1]
+			current = current[:len(current)-1]
+			if _, ok := depths[e]; ok {
+				continue
+			}
+			for _, fe := range faceEdges(e) {
+				depths[fe] = depth
+			}
+			for _, fe := range faceEdges(e) {
+				sym := fe.Sym()
+				if _, ok := depths[sym]; ok {
+					continue
+				}
+				if fe.IsConstrained() {
+					next = append(next, sym)
+					continue
+				}
+				current = append(current, sym)
+			}
+		}
+		current, next = next, nil
+	}
+	return depths
+}
+
+// frameEdge returns an edge between two of the frame points.
+func (sd *Subdivision) frameEdge() *quadedge.Edge {
+	var fe *quadedge.Edge
+	_ = sd.WalkAllEdges(func(e *quadedge.Edge) error {
+		if sd.isHardFrameEdge(e) {
+			fe = e
+			return ErrCancel
+		}
+		return nil
+	})
+	return fe
+}
+
+// InsideTriangles returns the triangles inside of the constraints, taking the
+// constraints to be the rings of polygons. A triangle is inside if an odd
+// number of constraints need to be crossed to get to it from the frame; so
+// the triangles in the holes of a polygon are not inside.
+func (sd *Subdivision) InsideTriangles() (triangles [][3]geometry.Point, err error) {
+	fe := sd.frameEdge()
+	if fe == nil {
+		return nil, ErrAssumptionFailed()
+	}
+	depths := constraintDepths(fe)
+	err = WalkAllTriangleEdges(sd.startingEdge, func(edges []*quadedge.Edge) error {
+		if len(edges) != 3 || depths[edges[0]]%2 == 0 {
+			return nil
+		}
+		pts := [3]geometry.Point{*edges[0].Orig(), *edges[1].Orig(), *edges[2].Orig()}
+		if sd.isFramePoint(pts[:]...) {
+			return nil
+		}
+		triangles = append(triangles, pts)
+		return nil
+	})
+	return triangles, err
+}
+
+// Seed is a point, and the label for the faces that can be reached from it.
+type Seed struct {
+	Point geometry.Point
+	Label int
+}
+
+// regions returns, for each edge, the region of the face on it's left. A
+// region is the faces that can be reached from each other without crossing
+// a constrained edge.
+func (sd *Subdivision) regions() map[*quadedge.Edge]int {
+	region := make(map[*quadedge.Edge]int)
+	n := 0
+	_ = WalkAllTriangleEdges(sd.startingEdge, func(edges []*quadedge.Edge) error {
+		if _, ok := region[edges[0]]; ok {
+			return nil
+		}
+		var toProcess quadedge.Stack
+		toProcess.Push(edges[0])
+		for toProcess.Length() > 0 {
+			e := toProcess.Pop()
+			if _, ok := region[e]; ok {
+				continue
+			}
+			for _, fe := range faceEdges(e) {
+				region[fe] = n
+			}
+			for _, fe := range faceEdges(e) {
+				if _, ok := region[fe.Sym()]; ok || fe.IsConstrained() {
+					continue
+				}
+				toProcess.Push(fe.Sym())
+			}
+		}
+		n++
+		return nil
+	})
+	return region
+}
+
+// LabelFaces labels the faces of the subdivision, by flood filling from the
+// face containing each seed point, without crossing constrained edges. Faces
+// that can't be reached from a seed are not labelled. If a face can be reached
+// from more then one seed, the label of the first one is used. The labels are
+// for the faces of the subdivision as it is; they need to be redone after the
+// subdivision is changed.
+func (sd *Subdivision) LabelFaces(seeds ...Seed) error {
+	region := sd.regions()
+	labels := make(map[int]int)
+	for _, seed := range seeds {
+		loc, ok := sd.Locate(seed.Point)
+		if !ok || loc.Edge == nil {
+			return ErrSeedNotLocated
+		}
+		r := region[loc.Edge]
+		if _, ok := labels[r]; !ok {
+			labels[r] = seed.Label
+		}
+	}
+
+	sd.faceLabels = make(map[*quadedge.Edge]int)
+	for e, r := range region {
+		if label, ok := labels[r]; ok {
+			// A Triangle is the face on the right of it's edge.
+			sd.faceLabels[e.Sym()] = label
+		}
+	}
+	return nil
+}
+
+// FaceLabel returns the label of the triangle, see LabelFaces.
+func (sd *Subdivision) FaceLabel(tri Triangle) (label int, ok bool) {
+	if tri.Edge == nil {
+		return 0, false
+	}
+	label, ok = sd.faceLabels[tri.Edge]
+	return label, ok
+}
@@ -0,0 +1,126 @@
+package subdivision
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/gdey/quad-edge/geometry"
+	"github.com/gdey/quad-edge/quadedge"
+)
+
+// ringsTestSubdivision returns a subdivision with a polygon, with a hole
+// that has an island in it, as constraints.
+func ringsTestSubdivision(t *testing.T) *Subdivision {
+	ctx := context.Background()
+	rings := [][][2]float64{
+		{{0, 0}, {100, 0}, {100, 100}, {0, 100}},
+		// a hole
+		{{20, 20}, {20, 60}, {60, 60}, {60, 20}},
+		// an island in the hole
+		{{30, 30}, {50, 30}, {40, 50}},
+	}
+	var pts [][2]float64
+	for _, ring := range rings {
+		pts = append(pts, ring...)
+	}
+	// points outside, and inside, of the rings
+	pts = append(pts, [2]float64{150, 50}, [2]float64{80, 80}, [2]float64{25, 50}, [2]float64{40, 40})
+	sd := insertPoints(t, pts)
+	vx := sd.VertexIndex()
+	for _, ring := range rings {
+		for i := range ring {
+			a, b := ring[i], ring[(i+1)%len(ring)]
+			if err := sd.InsertConstraint(ctx, vx, geometry.NewPoint(a[0], a[1]), geometry.NewPoint(b[0], b[1])); err != nil {
+				t.Fatalf("insert constraint, expected nil got %v", err)
+			}
+		}
+	}
+	return sd
+}
+
+func TestInsideTriangles(t *testing.T) {
+	sd := ringsTestSubdivision(t)
+	tris, err := sd.InsideTriangles()
+	if err != nil {
+		t.Fatalf("inside triangles, expected nil got %v", err)
+	}
+	var area float64
+	for _, tri := range tris {
+		a, b, c := geometry.UnwrapPoint(tri[0]), geometry.UnwrapPoint(tri[1]), geometry.UnwrapPoint(tri[2])
+		area += math.Abs((b[0]-a[0])*(c[1]-a[1])-(b[1]-a[1])*(c[0]-a[0])) / 2
+		for _, pt := range tri {
+			if geometry.ArePointsEqual(pt, geometry.NewPoint(150, 50)) || geometry.ArePointsEqual(pt, geometry.NewPoint(25, 50)) {
+				t.Errorf("triangle %v, expected to be outside", tri)
+			}
+		}
+	}
+	if expected := 100.0*100 - 40*40 + 20*20/2; area != expected {
+		t.Errorf("area, expected %v got %v", expected, area)
+	}
+}
+
+func TestLabelFaces(t *testing.T) {
+	const (
+		land = iota + 1
+		water
+		outside
+	)
+	sd := ringsTestSubdivision(t)
+	err := sd.LabelFaces(
+		Seed{Point: geometry.NewPoint(90, 90), Label: land},
+		Seed{Point: geometry.NewPoint(22, 40), Label: water},
+		Seed{Point: geometry.NewPoint(150, 10), Label: outside},
+		// same region as the first seed
+		Seed{Point: geometry.NewPoint(10, 10), Label: water},
+	)
+	if err != nil {
+		t.Fatalf("label faces, expected nil got %v", err)
+	}
+
+	in := func(pt [2]float64, min, max float64) bool {
+		return pt[0] > min && pt[0] < max && pt[1] > min && pt[1] < max
+	}
+	var n int
+	_ = WalkAllTriangleEdges(sd.startingEdge, func(edges []*quadedge.Edge) error {
+		if len(edges) != 3 {
+			return nil
+		}
+		var (
+			c     [2]float64
+			frame bool
+		)
+		for _, e := range edges {
+			pt := geometry.UnwrapPoint(*e.Orig())
+			c[0], c[1] = c[0]+pt[0]/3, c[1]+pt[1]/3
+			frame = frame || sd.isFramePoint(*e.Orig())
+		}
+		var (
+			expected   int
+			expectedOK = true
+		)
+		switch {
+		case frame || !in(c, 0, 100):
+			expected = outside
+		case !in(c, 20, 60):
+			expected = land
+		case geometry.Orientation(geometry.NewPoint(30, 30), geometry.NewPoint(50, 30), geometry.NewPoint(c[0], c[1])) > 0 &&
+			geometry.Orientation(geometry.NewPoint(50, 30), geometry.NewPoint(40, 50), geometry.NewPoint(c[0], c[1])) > 0 &&
+			geometry.Orientation(geometry.NewPoint(40, 50), geometry.NewPoint(30, 30), geometry.NewPoint(c[0], c[1])) > 0:
+			// the island isn't labelled
+			expectedOK = false
+		default:
+			expected = water
+		}
+		// A Triangle is the face on the right of it's edge.
+		label, ok := sd.FaceLabel(NewTriangle(edges[0].Sym()))
+		if ok != expectedOK || label != expected {
+			t.Errorf("triangle with centroid %v, expected %v %v got %v %v", c, expected, expectedOK, label, ok)
+		}
+		n++
+		return nil
+	})
+	if n == 0 {
+		t.Errorf("triangles, expected some got none")
+	}
+}
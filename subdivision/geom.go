@@ -35,27 +35,7 @@ func NewSubdivisionFromGeomLines(frame [3]geom.Point, lines []geom.Line) *Subdiv
 		geometry.NewPoint(frame[1][0], frame[1][1]),
 		geometry.NewPoint(frame[2][0], frame[2][1]),
 	)
-	indexMap := make(map[geometry.Point]*quadedge.Edge)
-	addEdgeToIndexMap := func(e *quadedge.Edge) {
-		if e == nil {
-			return
-		}
-		var ok bool
-		orig := *e.Orig()
-		dest := *e.Dest()
-		if _, ok = indexMap[orig]; !ok  {
-			indexMap[orig] = e
-		}
-		if _, ok = indexMap[dest]; !ok {
-			indexMap[dest] = e.Sym()
-		}
-	}
-
-	// Fill out the indexMap.
-	_ = sd.WalkAllEdges(func(e *quadedge.Edge) error {
-		addEdgeToIndexMap(e)
-		return nil
-	})
+	indexMap := sd.VertexIndex()
 
 	for _, l := range lines {
 		if l.LenghtSquared() == 0.0 {
@@ -64,8 +44,8 @@ func NewSubdivisionFromGeomLines(frame [3]geom.Point, lines []geom.Line) *Subdiv
 
 		p0 := geometry.NewPoint(l[0][0], l[0][1])
 		p1 := geometry.NewPoint(l[1][0], l[1][1])
-		p0edge := indexMap[p0]
-		p1edge := indexMap[p1]
+		p0edge, _ := indexMap.Get(p0)
+		p1edge, _ := indexMap.Get(p1)
 
 		if p0edge == nil && p1edge == nil {
 			// skipp edges that isn't in the network currently?
@@ -74,7 +54,7 @@ func NewSubdivisionFromGeomLines(frame [3]geom.Point, lines []geom.Line) *Subdiv
 
 		// assume both points are going to be added to the graph
 		sd.ptcount += 2
-		p0p1edge := quadedge.New()
+		p0p1edge := sd.pool.New()
 		p0p1edge.EndPoints(&p0, &p1)
 		if p0edge != nil {
 			// remove this point from count since it already
@@ -88,7 +68,7 @@ func NewSubdivisionFromGeomLines(frame [3]geom.Point, lines []geom.Line) *Subdiv
 			sd.ptcount--
 			quadedge.Splice(p1edge, p0p1edge.Sym())
 		}
-		addEdgeToIndexMap(p0p1edge)
+		indexMap.Add(p0p1edge)
 	}
 	return sd
 }
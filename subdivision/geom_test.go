@@ -104,7 +104,7 @@ func TestFindImmediateRightOfEdge(t *testing.T) {
 			var showDebug bool
 			se := edgeAtIndex(tc.seIdx)
 
-			gotFrom, gotTo := findImmediateRightOfEdges(se, tc.dest)
+			gotFrom, gotTo := findImmediateRightOfEdges(geometry.BackendKernel{}, se, tc.dest)
 
 			if gotFrom != from {
 				showDebug = true
@@ -227,7 +227,7 @@ func TestResolveEdge(t *testing.T) {
 			found := edgeAtIndex(tc.foundIdx)
 			se := edgeAtIndex(tc.seIdx)
 
-			gotFound := resolveEdge(se, tc.dest)
+			gotFound := resolveEdge(geometry.BackendKernel{}, se, tc.dest)
 
 			if gotFound != found {
 				showDebug = true
@@ -0,0 +1,124 @@
+package subdivision
+
+import (
+	"github.com/gdey/quad-edge/geometry"
+	"github.com/gdey/quad-edge/quadedge"
+)
+
+const (
+	// gridMinPoints is the number of points a subdivision needs before
+	// a grid is built, for fewer points walking from the starting edge
+	// is fast enough.
+	gridMinPoints = 64
+	// gridPointsPerCell is the average number of points in a cell of
+	// the grid when it is built.
+	gridPointsPerCell = 4
+)
+
+// grid is a bucket grid over the extent of the points in a subdivision.
+// Each cell holds an edge with an origin in, or near, that cell. It is
+// used as a hint to start the point location walk close to the point
+// being located. (Jump and walk, see Mücke, Saias and Zhu (1996).)
+//
+// The edges in the cells are not removed when they are swapped, so the
+// origin of an edge may have moved; they are still edges of the
+// subdivision, just possibly further away.
+type grid struct {
+	ext        [2][2]float64
+	cols, rows int
+	cells      []*quadedge.Edge
+	// size is the number of points the grid was built for
+	size int
+}
+
+// newGrid builds a grid for the given vertex index, size is the number of
+// points in the subdivision.
+func newGrid(frame [3]geometry.Point, vx *VertexIndex, size int) *grid {
+	var pts [][2]float64
+	vx.Each(func(e *quadedge.Edge) {
+		if isFramePoint(vx.kernel, frame, *e.Orig()) {
+			return
+		}
+		pts = append(pts, geometry.UnwrapPoint(*e.Orig()))
+	})
+	g := &grid{
+		ext:  geometry.Extent(pts...),
+		size: size,
+	}
+
+	// Try and keep the cells square.
+	ncells := len(pts)/gridPointsPerCell + 1
+	w, h := g.ext[1][0]-g.ext[0][0], g.ext[1][1]-g.ext[0][1]
+	g.cols, g.rows = 1, 1
+	for g.cols*g.rows < ncells {
+		if w/float64(g.cols) >= h/float64(g.rows) {
+			g.cols++
+		} else {
+			g.rows++
+		}
+	}
+	g.cells = make([]*quadedge.Edge, g.cols*g.rows)
+
+	vx.Each(func(e *quadedge.Edge) {
+		g.cells[g.cell(geometry.UnwrapPoint(*e.Orig()))] = e
+	})
+
+	// Fill empty cells with the edge of the closest previous cell, so
+	// every cell has a starting edge.
+	var last *quadedge.Edge
+	for i := range g.cells {
+		if g.cells[i] == nil {
+			g.cells[i] = last
+			continue
+		}
+		last = g.cells[i]
+	}
+	for i := 0; i < len(g.cells) && g.cells[i] == nil; i++ {
+		g.cells[i] = last
+	}
+	return g
+}
+
+// cell returns the index of the cell containing pt, points outside of
+// the extent of the grid are clamped to the closest cell.
+func (g *grid) cell(pt [2]float64) int {
+	idx := func(v, min, max float64, n int) int {
+		if max <= min {
+			return 0
+		}
+		i := int(float64(n) * (v - min) / (max - min))
+		switch {
+		case i < 0:
+			return 0
+		case i >= n:
+			return n - 1
+		default:
+			return i
+		}
+	}
+	x := idx(pt[0], g.ext[0][0], g.ext[1][0], g.cols)
+	y := idx(pt[1], g.ext[0][1], g.ext[1][1], g.rows)
+	return y*g.cols + x
+}
+
+// Edge returns an edge close to pt, or nil if there isn't one.
+func (g *grid) Edge(pt geometry.Point) *quadedge.Edge {
+	if g == nil {
+		return nil
+	}
+	e := g.cells[g.cell(geometry.UnwrapPoint(pt))]
+	if e == nil || e.ONext() == e {
+		// The edge has been deleted from the subdivision. If it has
+		// been reused it is still a good place to start a walk from.
+		return nil
+	}
+	return e
+}
+
+// Add records the edge e, for the origin of e.
+func (g *grid) Add(e *quadedge.Edge) {
+	if g == nil || e == nil || e.Orig() == nil {
+		return
+	}
+	g.cells[g.cell(geometry.UnwrapPoint(*e.Orig()))] = e
+}
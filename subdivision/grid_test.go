@@ -0,0 +1,113 @@
+package subdivision
+
+import (
+	"math/rand"
+	"runtime"
+	"testing"
+
+	"github.com/gdey/quad-edge/geometry"
+)
+
+func randomPoints(n int, seed int64) (pts [][2]float64) {
+	rnd := rand.New(rand.NewSource(seed))
+	for i := 0; i < n; i++ {
+		pts = append(pts, [2]float64{float64(rnd.Intn(1 << 20)), float64(rnd.Intn(1 << 20))})
+	}
+	return pts
+}
+
+// insertPoints inserts the points, in the given order, in to a new subdivision.
+func insertPoints(tb testing.TB, pts [][2]float64) *Subdivision {
+	tri := geometry.TriangleContaining(pts...)
+	sd := New(
+		geometry.NewPoint(tri[0][0], tri[0][1]),
+		geometry.NewPoint(tri[1][0], tri[1][1]),
+		geometry.NewPoint(tri[2][0], tri[2][1]),
+	)
+	for _, pt := range pts {
+		if !sd.InsertSite(geometry.NewPoint(pt[0], pt[1])) {
+			tb.Fatalf("failed to insert point %v", pt)
+		}
+	}
+	return sd
+}
+
+func TestGrid(t *testing.T) {
+	pts := randomPoints(5000, 1)
+	sd := insertPoints(t, pts)
+	if sd.grid == nil {
+		t.Fatalf("grid, expected non-nil got nil")
+	}
+	if sd.grid.size*2 <= sd.ptcount {
+		t.Errorf("grid size, expected more then %v got %v", sd.ptcount/2, sd.grid.size)
+	}
+	vx := sd.VertexIndex()
+	for _, pt := range pts {
+		if _, ok := vx.Get(geometry.NewPoint(pt[0], pt[1])); !ok {
+			t.Errorf("vertex index, expected %v got nothing", pt)
+		}
+		e := sd.grid.Edge(geometry.NewPoint(pt[0], pt[1]))
+		if e == nil {
+			t.Fatalf("grid edge for %v, expected edge got nil", pt)
+		}
+	}
+}
+
+func TestLocateFallback(t *testing.T) {
+	pts := randomPoints(500, 2)
+	sd := insertPoints(t, pts)
+	// A limit of one step makes most of the walks fall back to testing
+	// every edge; the edge found is still returned.
+	for _, pt := range pts {
+		x := geometry.NewPoint(pt[0], pt[1])
+		e, ok := locate(sd.kernel, sd.startingEdge, x, 1)
+		if !ok || e == nil {
+			t.Fatalf("locate %v, expected an edge got %v %v", pt, e, ok)
+		}
+		if _, ok = testEdge(sd.kernel, x, e); !ok {
+			t.Errorf("locate %v, expected the edge of the point got %v", pt, e.AsGeomLine())
+		}
+	}
+}
+
+func BenchmarkInsertSiteRandom(b *testing.B) {
+	pts := randomPoints(10000, 1)
+	for i := 0; i < b.N; i++ {
+		insertPoints(b, pts)
+	}
+}
+
+// BenchmarkInsertSitePool compares building a mesh with the edges and points
+// from the subdivision's pool, to allocating each of them on it's own.
+func BenchmarkInsertSitePool(b *testing.B) {
+	pts := randomPoints(100000, 1)
+	tri := geometry.TriangleContaining(pts...)
+	for _, withPool := range []bool{true, false} {
+		name := "heap"
+		if withPool {
+			name = "pool"
+		}
+		b.Run(name, func(b *testing.B) {
+			b.ReportAllocs()
+			var before, after runtime.MemStats
+			runtime.ReadMemStats(&before)
+			for i := 0; i < b.N; i++ {
+				sd := New(
+					geometry.NewPoint(tri[0][0], tri[0][1]),
+					geometry.NewPoint(tri[1][0], tri[1][1]),
+					geometry.NewPoint(tri[2][0], tri[2][1]),
+				)
+				if !withPool {
+					sd.pool = nil
+				}
+				for _, pt := range pts {
+					if !sd.InsertSite(geometry.NewPoint(pt[0], pt[1])) {
+						b.Fatalf("failed to insert point %v", pt)
+					}
+				}
+			}
+			runtime.ReadMemStats(&after)
+			b.ReportMetric(float64(after.NumGC-before.NumGC)/float64(b.N), "gc/op")
+		})
+	}
+}
@@ -0,0 +1,116 @@
+package subdivision
+
+import (
+	"fmt"
+
+	"github.com/gdey/quad-edge/geometry"
+	"github.com/gdey/quad-edge/quadedge"
+)
+
+// LocationType describes where a point is in a subdivision.
+type LocationType uint
+
+const (
+	// OUTSIDE the point is outside the hull of the points in the subdivision.
+	OUTSIDE = LocationType(iota)
+	// IN_TRIANGLE the point is inside of a triangle.
+	IN_TRIANGLE
+	// ON_EDGE the point is on an edge, but not on one of it's vertices.
+	ON_EDGE
+	// ON_VERTEX the point is a vertex of the subdivision.
+	ON_VERTEX
+)
+
+func (l LocationType) String() string {
+	switch l {
+	case OUTSIDE:
+		return "OUTSIDE"
+	case IN_TRIANGLE:
+		return "IN_TRIANGLE"
+	case ON_EDGE:
+		return "ON_EDGE"
+	case ON_VERTEX:
+		return "ON_VERTEX"
+	default:
+		return fmt.Sprintf("UNKNOWN(%v)", int(l))
+	}
+}
+
+// Location is the result of locating a point in a subdivision.
+type Location struct {
+	Type LocationType
+	// Edge depends on the Type of the location:
+	//    IN_TRIANGLE : an edge of the triangle, with the triangle on it's left.
+	//    ON_EDGE     : the edge the point is on.
+	//    ON_VERTEX   : an edge with the point as it's origin.
+	//    OUTSIDE     : nil if the point is outside of the frame, otherwise
+	//                  the edge as for the other types.
+	Edge *quadedge.Edge
+}
+
+// Triangle returns the triangle containing the point. For a point on an
+// edge it is the triangle on the left of the edge.
+func (l Location) Triangle() Triangle {
+	if l.Edge == nil {
+		return Triangle{}
+	}
+	// A Triangle is the face on the right of it's edge.
+	return NewTriangle(l.Edge.Sym())
+}
+
+func (l Location) String() string {
+	if l.Edge == nil {
+		return l.Type.String()
+	}
+	return fmt.Sprintf("%v %v", l.Type, l.Edge.AsGeomLine())
+}
+
+// Locate returns where the point is in the subdivision. If the point
+// could not be located false is returned.
+func (sd *Subdivision) Locate(pt geometry.Point) (Location, bool) {
+	k := sd.kernel
+	fo := k.Orientation(sd.frame[0], sd.frame[1], sd.frame[2])
+	for i := range sd.frame {
+		if k.Orientation(sd.frame[i], sd.frame[(i+1)%3], pt) == -fo {
+			// The point is on the other side of a frame edge.
+			return Location{Type: OUTSIDE}, true
+		}
+	}
+
+	e, ok := sd.locate(pt)
+	if !ok || e == nil {
+		return Location{}, false
+	}
+
+	// The point is on, or to the left of, the edges of the face left of e.
+	edges := [3]*quadedge.Edge{e, e.LNext(), e.LPrev()}
+	loc := Location{Type: IN_TRIANGLE, Edge: e}
+	for _, le := range edges {
+		if ptEqual(k, pt, le.Orig()) {
+			loc = Location{Type: ON_VERTEX, Edge: le}
+			break
+		}
+		if onEdge(k, pt, le) {
+			loc = Location{Type: ON_EDGE, Edge: le}
+		}
+	}
+	if loc.Type == IN_TRIANGLE && !loc.Triangle().intersectsPoint(k, pt) {
+		return Location{}, false
+	}
+
+	switch loc.Type {
+	case ON_VERTEX:
+		if sd.isFramePoint(*loc.Edge.Orig()) {
+			loc.Type = OUTSIDE
+		}
+	case ON_EDGE:
+		if sd.isFrameEdge(loc.Edge) {
+			loc.Type = OUTSIDE
+		}
+	case IN_TRIANGLE:
+		if sd.isFrameEdge(edges[:]...) {
+			loc.Type = OUTSIDE
+		}
+	}
+	return loc, true
+}
@@ -0,0 +1,59 @@
+package subdivision
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/gdey/quad-edge/geometry"
+)
+
+func TestLocate(t *testing.T) {
+	// A square split in to two triangles along (10,0) -> (0,10)
+	sd := insertPoints(t, [][2]float64{{0, 0}, {10, 0}, {10, 10}, {0, 10}})
+
+	type tcase struct {
+		pt       [2]float64
+		expected LocationType
+	}
+	fn := func(tc tcase) func(*testing.T) {
+		return func(t *testing.T) {
+			pt := geometry.NewPoint(tc.pt[0], tc.pt[1])
+			loc, ok := sd.Locate(pt)
+			if !ok {
+				t.Fatalf("located, expected true got false")
+			}
+			if loc.Type != tc.expected {
+				t.Fatalf("type, expected %v got %v", tc.expected, loc)
+			}
+			switch loc.Type {
+			case ON_VERTEX:
+				if !geometry.ArePointsEqual(*loc.Edge.Orig(), pt) {
+					t.Errorf("vertex, expected %v got %v", tc.pt, geometry.UnwrapPoint(*loc.Edge.Orig()))
+				}
+			case ON_EDGE:
+				if !geometry.IsPointOn(geometry.Line{*loc.Edge.Orig(), *loc.Edge.Dest()}, pt) {
+					t.Errorf("edge, expected %v to be on %v", tc.pt, loc.Edge.AsGeomLine())
+				}
+			case IN_TRIANGLE:
+				if !loc.Triangle().IntersectsPoint(pt) {
+					t.Errorf("triangle, expected %v to be in %v", tc.pt, loc.Triangle().AsGeom())
+				}
+			}
+		}
+	}
+	tests := []tcase{
+		{pt: [2]float64{2, 7}, expected: IN_TRIANGLE},
+		{pt: [2]float64{7, 8}, expected: IN_TRIANGLE},
+		{pt: [2]float64{3, 7}, expected: ON_EDGE},
+		{pt: [2]float64{10, 10}, expected: ON_VERTEX},
+		{pt: [2]float64{0, 0}, expected: ON_VERTEX},
+		{pt: [2]float64{5, 0}, expected: ON_EDGE},
+		{pt: [2]float64{0, 5}, expected: ON_EDGE},
+		{pt: [2]float64{20, 20}, expected: OUTSIDE},
+		{pt: [2]float64{-5, 5}, expected: OUTSIDE},
+		{pt: [2]float64{1e9, 1e9}, expected: OUTSIDE},
+	}
+	for i, tc := range tests {
+		t.Run(strconv.Itoa(i), fn(tc))
+	}
+}
@@ -0,0 +1,156 @@
+package subdivision
+
+import (
+	"math"
+
+	"github.com/gdey/quad-edge/geometry"
+	"github.com/gdey/quad-edge/quadedge"
+)
+
+// NaturalNeighbor is a natural neighbour of a point, and it's Sibson weight.
+type NaturalNeighbor struct {
+	Vertex
+	Weight float64
+}
+
+// NaturalNeighbors returns the natural neighbours of the point, the vertices
+// that would share a Voronoi edge with it if it was inserted, and their
+// Sibson weights; the part of the Voronoi cell of the point that would be
+// taken from the cell of each of them. The weights add up to one. If the point
+// is a vertex it is the only neighbour. If the point is on a constraint, such
+// as the hull of a TIN, the end points of the constraint's edge are the
+// neighbours, weighted linearly along it.
+//
+// The subdivision is not changed; the triangles that would be removed by
+// inserting the point are the triangles with the point inside of their
+// circumcircle. These are found by walking out from the triangle containing
+// the point, without crossing constraints. ErrOutsideHull is returned if any
+// of them are attached to the frame.
+func (sd *Subdivision) NaturalNeighbors(pt geometry.Point) ([]NaturalNeighbor, error) {
+	k := sd.kernel
+	loc, ok := sd.Locate(pt)
+	if !ok || loc.Type == OUTSIDE {
+		return nil, ErrOutsideHull
+	}
+	if loc.Type == ON_VERTEX {
+		v := Vertex{Point: *loc.Edge.Orig(), Data: loc.Edge.OrigData()}
+		return []NaturalNeighbor{{Vertex: v, Weight: 1}}, nil
+	}
+	if loc.Type == ON_EDGE && loc.Edge.IsConstrained() {
+		// The cavity can't cross the constraint, and would have the
+		// point on it's boundary.
+		e := loc.Edge
+		a, b, x := geometry.UnwrapPoint(*e.Orig()), geometry.UnwrapPoint(*e.Dest()), geometry.UnwrapPoint(pt)
+		dx, dy := b[0]-a[0], b[1]-a[1]
+		t := ((x[0]-a[0])*dx + (x[1]-a[1])*dy) / (dx*dx + dy*dy)
+		return []NaturalNeighbor{
+			{Vertex: Vertex{Point: *e.Orig(), Data: e.OrigData()}, Weight: 1 - t},
+			{Vertex: Vertex{Point: *e.Dest(), Data: e.Sym().OrigData()}, Weight: t},
+		}, nil
+	}
+
+	// cavity holds the edges of the faces, on their left, that would be
+	// removed.
+	cavity := make(map[*quadedge.Edge]bool)
+	var toProcess quadedge.Stack
+	toProcess.Push(loc.Edge)
+	for toProcess.Length() > 0 {
+		e := toProcess.Pop()
+		if cavity[e] {
+			continue
+		}
+		a, b, c := *e.Orig(), *e.Dest(), *e.LNext().Dest()
+		if sd.isFramePoint(a, b, c) {
+			return nil, ErrOutsideHull
+		}
+		for _, fe := range faceEdges(e) {
+			cavity[fe] = true
+		}
+		for _, fe := range faceEdges(e) {
+			sym := fe.Sym()
+			if cavity[sym] || fe.IsConstrained() {
+				continue
+			}
+			a, b, c := *sym.Orig(), *sym.Dest(), *sym.LNext().Dest()
+			if k.InCircle(a, b, c, pt)*k.Orientation(a, b, c) > 0 {
+				toProcess.Push(sym)
+			}
+		}
+	}
+
+	// The edges of the boundary of the cavity, by their origin; the cavity is
+	// on their left.
+	boundary := make(map[[2]float64]*quadedge.Edge)
+	var first *quadedge.Edge
+	for e := range cavity {
+		if !cavity[e.Sym()] {
+			boundary[geometry.UnwrapPoint(*e.Orig())] = e
+			first = e
+		}
+	}
+
+	x := geometry.UnwrapPoint(pt)
+	// center returns the circumcenter of the triangle of x and the boundary
+	// edge e; a vertex of the Voronoi cell of x.
+	center := func(e *quadedge.Edge) [2]float64 {
+		return circumcenter(x, geometry.UnwrapPoint(*e.Orig()), geometry.UnwrapPoint(*e.Dest()))
+	}
+
+	var (
+		neighbors []NaturalNeighbor
+		total     float64
+	)
+	for in := first; ; {
+		out, ok := boundary[geometry.UnwrapPoint(*in.Dest())]
+		if !ok || len(neighbors) == len(boundary) {
+			// The boundary is not a simple ring.
+			return nil, ErrAssumptionFailed()
+		}
+		// The area taken from the cell of the vertex is bounded by the
+		// Voronoi edge between it and x, and the circumcenters of the
+		// triangles around it that would be removed.
+		ring := [][2]float64{center(out)}
+		for s := out; s != in.Sym(); s = s.ONext() {
+			a, b, c := geometry.UnwrapPoint(*s.Orig()), geometry.UnwrapPoint(*s.Dest()), geometry.UnwrapPoint(*s.LNext().Dest())
+			ring = append(ring, circumcenter(a, b, c))
+		}
+		ring = append(ring, center(in))
+		area := math.Abs(ringArea(ring))
+		total += area
+		neighbors = append(neighbors, NaturalNeighbor{
+			Vertex: Vertex{Point: *out.Orig(), Data: out.OrigData()},
+			Weight: area,
+		})
+		if in = out; in == first {
+			break
+		}
+	}
+	if total == 0 || math.IsNaN(total) || math.IsInf(total, 0) {
+		return nil, ErrAssumptionFailed()
+	}
+	for i := range neighbors {
+		neighbors[i].Weight /= total
+	}
+	return neighbors, nil
+}
+
+// InterpolateNaturalNeighbor returns the z at x, y; using natural neighbour
+// (Sibson) interpolation of the z of the vertices. See NaturalNeighbors.
+func (tin *TIN) InterpolateNaturalNeighbor(x, y float64) (float64, error) {
+	neighbors, err := tin.NaturalNeighbors(geometry.NewPoint(x, y))
+	if err == ErrOutsideHull {
+		return 0, ErrOutsideTIN
+	}
+	if err != nil {
+		return 0, err
+	}
+	var z float64
+	for _, n := range neighbors {
+		nz, ok := n.Data.(float64)
+		if !ok {
+			return 0, ErrMissingZ
+		}
+		z += n.Weight * nz
+	}
+	return z, nil
+}
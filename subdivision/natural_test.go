@@ -0,0 +1,109 @@
+package subdivision
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/gdey/quad-edge/geometry"
+)
+
+func TestNaturalNeighbors(t *testing.T) {
+	ctx := context.Background()
+	tin, err := NewTIN(ctx, [][3]float64{{0, 0, 1}, {10, 0, 2}, {10, 10, 3}, {0, 10, 4}})
+	if err != nil {
+		t.Fatalf("new tin, expected nil got %v", err)
+	}
+	// The center takes a quarter from each corner.
+	neighbors, err := tin.NaturalNeighbors(geometry.NewPoint(5, 5))
+	if err != nil {
+		t.Fatalf("natural neighbors, expected nil got %v", err)
+	}
+	if len(neighbors) != 4 {
+		t.Fatalf("number of neighbors, expected 4 got %v", len(neighbors))
+	}
+	for _, n := range neighbors {
+		if math.Abs(n.Weight-0.25) > 1e-12 {
+			t.Errorf("weight of %v, expected 0.25 got %v", n.Point, n.Weight)
+		}
+	}
+
+	// A vertex is it's only neighbour.
+	neighbors, err = tin.NaturalNeighbors(geometry.NewPoint(10, 0))
+	if err != nil {
+		t.Fatalf("natural neighbors, expected nil got %v", err)
+	}
+	if len(neighbors) != 1 || neighbors[0].Weight != 1 || neighbors[0].Data != 2.0 {
+		t.Errorf("neighbors of a vertex, expected the vertex got %v", neighbors)
+	}
+
+	if _, err := tin.NaturalNeighbors(geometry.NewPoint(-5, 5)); err != ErrOutsideHull {
+		t.Errorf("natural neighbors outside, expected %v got %v", ErrOutsideHull, err)
+	}
+}
+
+func TestTINInterpolateNaturalNeighbor(t *testing.T) {
+	ctx := context.Background()
+	// Natural neighbour interpolation is exact for a plane.
+	plane := func(x, y float64) float64 { return 2*x - 3*y + 7 }
+	var pts [][3]float64
+	for _, pt := range randomPoints(200, 9) {
+		pts = append(pts, [3]float64{pt[0], pt[1], plane(pt[0], pt[1])})
+	}
+	tin, err := NewTIN(ctx, pts)
+	if err != nil {
+		t.Fatalf("new tin, expected nil got %v", err)
+	}
+
+	rnd := rand.New(rand.NewSource(1))
+	for i := 0; i < 200; i++ {
+		// Away from the hull, where the constraints would get in the way.
+		x, y := float64(1<<18+rnd.Intn(1<<19)), float64(1<<18+rnd.Intn(1<<19))
+		neighbors, err := tin.NaturalNeighbors(geometry.NewPoint(x, y))
+		if err != nil {
+			t.Errorf("natural neighbors of %v %v, expected nil got %v", x, y, err)
+			continue
+		}
+		// The weights are the local coordinates of the point.
+		var sum, sx, sy float64
+		for _, n := range neighbors {
+			p := geometry.UnwrapPoint(n.Point)
+			sum += n.Weight
+			sx += n.Weight * p[0]
+			sy += n.Weight * p[1]
+		}
+		if math.Abs(sum-1) > 1e-9 || math.Abs(sx-x) > 1e-6 || math.Abs(sy-y) > 1e-6 {
+			t.Errorf("weights of %v %v, expected sum 1 at the point got %v at %v %v", x, y, sum, sx, sy)
+		}
+
+		z, err := tin.InterpolateNaturalNeighbor(x, y)
+		if err != nil {
+			t.Errorf("interpolate %v %v, expected nil got %v", x, y, err)
+			continue
+		}
+		if expected := plane(x, y); math.Abs(z-expected) > 1e-6 {
+			t.Errorf("interpolate %v %v, expected %v got %v", x, y, expected, z)
+		}
+	}
+
+	// Points on the hull, which is made of constraints.
+	square := [][3]float64{{0, 0, 1}, {10, 0, 2}, {10, 10, 3}, {0, 10, 4}, {5, 4, 5}}
+	if tin, err = NewTIN(ctx, square); err != nil {
+		t.Fatalf("new tin, expected nil got %v", err)
+	}
+	for _, pt := range [][2]float64{{5, 10}, {5, 0}, {0, 5}, {10, 5}, {2, 0}} {
+		expected, err := tin.InterpolateZ(pt[0], pt[1])
+		if err != nil {
+			t.Fatalf("interpolate z %v, expected nil got %v", pt, err)
+		}
+		z, err := tin.InterpolateNaturalNeighbor(pt[0], pt[1])
+		if err != nil {
+			t.Errorf("interpolate %v, expected nil got %v", pt, err)
+			continue
+		}
+		if math.IsNaN(z) || math.Abs(z-expected) > 1e-9 {
+			t.Errorf("interpolate %v, expected %v got %v", pt, expected, z)
+		}
+	}
+}
@@ -0,0 +1,148 @@
+package subdivision
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+
+	"github.com/gdey/quad-edge/geometry"
+)
+
+// InsertionOrder is the order NewForPoints inserts the points in.
+type InsertionOrder uint
+
+const (
+	// ORDER_LEXICOGRAPHIC inserts the points sorted by x then y. This is
+	// the default.
+	ORDER_LEXICOGRAPHIC = InsertionOrder(iota)
+	// ORDER_RANDOM inserts the points in a random order.
+	ORDER_RANDOM
+	// ORDER_HILBERT_BRIO inserts the points in a biased randomized
+	// insertion order (BRIO), with the points of each round sorted along
+	// a Hilbert curve. See Amenta, Choi and Rote (2003), "Incremental
+	// Constructions con BRIO".
+	ORDER_HILBERT_BRIO
+)
+
+func (o InsertionOrder) String() string {
+	switch o {
+	case ORDER_LEXICOGRAPHIC:
+		return "ORDER_LEXICOGRAPHIC"
+	case ORDER_RANDOM:
+		return "ORDER_RANDOM"
+	case ORDER_HILBERT_BRIO:
+		return "ORDER_HILBERT_BRIO"
+	default:
+		return fmt.Sprintf("UNKNOWN(%v)", int(o))
+	}
+}
+
+// WithInsertionOrder sets the order NewForPoints inserts the points in. The
+// random orders use a fixed seed, so the same points are always inserted in
+// the same order.
+func WithInsertionOrder(order InsertionOrder) Option {
+	return func(sd *Subdivision) {
+		sd.order = order
+	}
+}
+
+const (
+	// orderSeed is the seed for the random insertion orders.
+	orderSeed = 1
+	// brioMinRound is the smallest number of points in the first round
+	// of a BRIO.
+	brioMinRound = 64
+	// hilbertOrder is the number of bits, for each coordinate, of the
+	// grid the points are snapped to for their Hilbert index.
+	hilbertOrder = 16
+)
+
+// apply reorders the points, which are sorted by x then y, in to the
+// insertion order.
+func (o InsertionOrder) apply(pts []geometry.Point) {
+	switch o {
+	case ORDER_RANDOM:
+		rnd := rand.New(rand.NewSource(orderSeed))
+		rnd.Shuffle(len(pts), func(i, j int) { pts[i], pts[j] = pts[j], pts[i] })
+
+	case ORDER_HILBERT_BRIO:
+		rnd := rand.New(rand.NewSource(orderSeed))
+		rnd.Shuffle(len(pts), func(i, j int) { pts[i], pts[j] = pts[j], pts[i] })
+		// The rounds are the last half of the points, the quarter before
+		// that, and so on.
+		for end := len(pts); end > 0; {
+			start := end / 2
+			if start < brioMinRound {
+				start = 0
+			}
+			hilbertSort(pts[start:end])
+			end = start
+		}
+	}
+}
+
+// hilbertSort sorts the points by where they are along a Hilbert curve
+// filling their extent.
+func hilbertSort(pts []geometry.Point) {
+	if len(pts) < 2 {
+		return
+	}
+	minx, miny := math.Inf(1), math.Inf(1)
+	maxx, maxy := math.Inf(-1), math.Inf(-1)
+	for _, pt := range pts {
+		p := geometry.UnwrapPoint(pt)
+		minx, miny = math.Min(minx, p[0]), math.Min(miny, p[1])
+		maxx, maxy = math.Max(maxx, p[0]), math.Max(maxy, p[1])
+	}
+	size := math.Max(maxx-minx, maxy-miny)
+	if size == 0 {
+		return
+	}
+
+	const side = 1<<hilbertOrder - 1
+	keys := make([]uint64, len(pts))
+	for i, pt := range pts {
+		p := geometry.UnwrapPoint(pt)
+		x := uint32((p[0] - minx) / size * side)
+		y := uint32((p[1] - miny) / size * side)
+		keys[i] = hilbertIndex(x, y)
+	}
+	sort.Sort(byKey{pts: pts, keys: keys})
+}
+
+// hilbertIndex returns the distance along a Hilbert curve, of hilbertOrder,
+// of the cell x, y.
+func hilbertIndex(x, y uint32) (d uint64) {
+	for s := uint32(1) << (hilbertOrder - 1); s > 0; s /= 2 {
+		var rx, ry uint32
+		if x&s > 0 {
+			rx = 1
+		}
+		if y&s > 0 {
+			ry = 1
+		}
+		d += uint64(s) * uint64(s) * uint64((3*rx)^ry)
+		// Rotate the quadrant, so the curve is continuous.
+		if ry == 0 {
+			if rx == 1 {
+				x, y = s-1-x&(s-1), s-1-y&(s-1)
+			}
+			x, y = y, x
+		}
+	}
+	return d
+}
+
+// byKey sorts points by their keys.
+type byKey struct {
+	pts  []geometry.Point
+	keys []uint64
+}
+
+func (b byKey) Len() int           { return len(b.pts) }
+func (b byKey) Less(i, j int) bool { return b.keys[i] < b.keys[j] }
+func (b byKey) Swap(i, j int) {
+	b.pts[i], b.pts[j] = b.pts[j], b.pts[i]
+	b.keys[i], b.keys[j] = b.keys[j], b.keys[i]
+}
@@ -0,0 +1,79 @@
+package subdivision
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gdey/quad-edge/geometry"
+)
+
+func TestHilbertIndex(t *testing.T) {
+	// The curve visits every cell once, moving to a neighbouring cell
+	// each step.
+	const n = 1 << 4
+	cells := make(map[uint64][2]int)
+	for x := 0; x < n; x++ {
+		for y := 0; y < n; y++ {
+			// Cells of a 16 by 16 grid, scaled to the full curve.
+			d := hilbertIndex(uint32(x<<(hilbertOrder-4)), uint32(y<<(hilbertOrder-4)))
+			d >>= 2 * (hilbertOrder - 4)
+			if _, ok := cells[d]; ok {
+				t.Fatalf("index %v, expected once got %v and %v", d, cells[d], [2]int{x, y})
+			}
+			cells[d] = [2]int{x, y}
+		}
+	}
+	for d := uint64(1); d < n*n; d++ {
+		a, b := cells[d-1], cells[d]
+		if dx, dy := a[0]-b[0], a[1]-b[1]; dx*dx+dy*dy != 1 {
+			t.Errorf("index %v, expected next to %v got %v", d, a, b)
+		}
+	}
+}
+
+func TestWithInsertionOrder(t *testing.T) {
+	ctx := context.Background()
+	pts := randomPoints(3000, 11)
+	expected := make(map[[3][2]float64]bool)
+	tris, err := NewForPoints(ctx, append([][2]float64(nil), pts...)).Triangles(true)
+	if err != nil {
+		t.Fatalf("triangles, expected nil got %v", err)
+	}
+	for _, tri := range tris {
+		expected[triangleKey(tri)] = true
+	}
+
+	for _, order := range []InsertionOrder{ORDER_LEXICOGRAPHIC, ORDER_RANDOM, ORDER_HILBERT_BRIO} {
+		t.Run(order.String(), func(t *testing.T) {
+			sites := make([]geometry.Point, len(pts))
+			for i, pt := range pts {
+				sites[i] = geometry.NewPoint(pt[0], pt[1])
+			}
+			order.apply(sites)
+			seen := make(map[[2]float64]bool)
+			for _, pt := range sites {
+				seen[geometry.UnwrapPoint(pt)] = true
+			}
+			for _, pt := range pts {
+				if !seen[pt] {
+					t.Fatalf("point %v, expected in the insertion order", pt)
+				}
+			}
+
+			// The order does not change the triangulation.
+			sd := NewForPoints(ctx, append([][2]float64(nil), pts...), WithInsertionOrder(order))
+			tris, err := sd.Triangles(true)
+			if err != nil {
+				t.Fatalf("triangles, expected nil got %v", err)
+			}
+			if len(tris) != len(expected) {
+				t.Errorf("number of triangles, expected %v got %v", len(expected), len(tris))
+			}
+			for _, tri := range tris {
+				if key := triangleKey(tri); !expected[key] {
+					t.Errorf("triangle %v, not expected", key)
+				}
+			}
+		})
+	}
+}
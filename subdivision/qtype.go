@@ -53,27 +53,34 @@ func TestClassify(lbl string, a, b, c geometry.Point) QType {
 }
 
 func Classify(a, b, c geometry.Point) QType {
+	return classify(geometry.BackendKernel{}, a, b, c)
+}
+
+// classify is Classify using the predicates of the given kernel.
+func classify(k geometry.Kernel, a, b, c geometry.Point) QType {
 	aa := geometry.Sub(c, b)
 	bb := geometry.Sub(a, b)
 
-	sa := geometry.CrossProduct(aa, bb)
+	// The orientation of b,c,a is the sign of the cross product of
+	// aa and bb, but computed with a robust sign.
+	sa := k.Orientation(b, c, a)
 
 	mab := geometry.Mul(aa, bb)
 
 	mabuw := geometry.UnwrapPoint(mab)
 
 	switch {
-	case sa > 0.0:
+	case sa > 0:
 		return LEFT
-	case sa < 0.0:
+	case sa < 0:
 		return RIGHT
 	case mabuw[0] < 0.0 || mabuw[1] < 0.0:
 		return BEHIND
 	case geometry.Magn(aa) < geometry.Magn(bb):
 		return BEYOND
-	case geometry.ArePointsEqual(a, b):
+	case k.ArePointsEqual(a, b):
 		return ORIGIN
-	case geometry.ArePointsEqual(a, c):
+	case k.ArePointsEqual(a, c):
 		return DESTINATION
 	default:
 		return BETWEEN
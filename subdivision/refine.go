@@ -0,0 +1,356 @@
+package subdivision
+
+import (
+	"context"
+	"math"
+	"sort"
+
+	"github.com/gdey/quad-edge/geometry"
+	"github.com/gdey/quad-edge/quadedge"
+	"github.com/go-spatial/geom/cmp"
+)
+
+// RefineOptions are the limits on the triangles of a refined subdivision.
+type RefineOptions struct {
+	// MinAngle is the smallest angle, in degrees, allowed in a triangle;
+	// zero means no limit. Refinement is only guaranteed to finish for
+	// angles up to about 20.7 degrees, and if the constraints and the hull
+	// don't meet at small angles.
+	MinAngle float64
+	// MaxArea is the largest area allowed for a triangle; zero means no limit.
+	MaxArea float64
+	// MaxSteiner, if not zero, is the most points that will be inserted;
+	// refinement stops once it is reached.
+	MaxSteiner int
+}
+
+// Refine inserts points in to the subdivision until none of it's triangles,
+// not attached to the frame, are skinny or too big, as given by opts. The
+// inserted (Steiner) points are returned. The vertexIndex, if not nil, is
+// kept in sync with the subdivision.
+//
+// This is Ruppert's Delaunay refinement algorithm, Ruppert (1995), "A Delaunay
+// Refinement Algorithm for Quality 2-Dimensional Mesh Generation". The
+// constraints and the edges of the convex hull are the segments; the edges
+// of the hull are inserted as constraints, so the region refined does not
+// change as points are inserted. A segment with a
+// vertex inside of it's diametral circle is encroached, and is split at it's
+// midpoint. A bad triangle is split by inserting it's circumcenter, unless the
+// circumcenter would encroach a segment, in which case the segment is split.
+//
+// With the int64 backend the inserted points are truncated, refinement will
+// usually end with ErrSegmentTooShort before the limits are reached.
+func (sd *Subdivision) Refine(ctx context.Context, vertexIndex *VertexIndex, opts RefineOptions) (steiner []geometry.Point, err error) {
+	if vertexIndex == nil {
+		vertexIndex = sd.VertexIndex()
+	}
+	if err = sd.constrainHull(ctx, vertexIndex); err != nil {
+		return nil, err
+	}
+
+	// segments are the edges to check for encroachment, triangles the
+	// edges with a triangle to check on their left.
+	var segments, triangles []*quadedge.Edge
+	queue := func(e *quadedge.Edge) {
+		segments = append(segments, e)
+		triangles = append(triangles, e, e.Sym())
+	}
+	_ = sd.WalkAllEdges(func(e *quadedge.Edge) error {
+		queue(e)
+		return nil
+	})
+	// added records the inserted point, and queues the edges around it.
+	added := func(pt geometry.Point) {
+		steiner = append(steiner, pt)
+		e, ok := vertexIndex.Get(pt)
+		if !ok {
+			return
+		}
+		for s := e; ; {
+			queue(s)
+			queue(s.LNext())
+			if s = s.ONext(); s == e {
+				break
+			}
+		}
+	}
+	split := func(e *quadedge.Edge) error {
+		pt, inserted, err := sd.splitSegment(ctx, vertexIndex, e)
+		if inserted {
+			added(pt)
+		}
+		return err
+	}
+
+	for {
+		if err = ctx.Err(); err != nil {
+			return steiner, err
+		}
+		if opts.MaxSteiner > 0 && len(steiner) >= opts.MaxSteiner {
+			return steiner, nil
+		}
+
+		// Encroached segments are split first.
+		if len(segments) > 0 {
+			e := segments[0]
+			segments = segments[1:]
+			if isDeleted(e) || !e.IsConstrained() || !sd.isEncroached(e) {
+				continue
+			}
+			if err = split(e); err != nil {
+				return steiner, err
+			}
+			continue
+		}
+
+		if len(triangles) == 0 {
+			return steiner, nil
+		}
+		e := triangles[0]
+		triangles = triangles[1:]
+		if isDeleted(e) || !sd.isBadTriangle(e, opts) {
+			continue
+		}
+
+		a, b, c := geometry.UnwrapPoint(*e.Orig()), geometry.UnwrapPoint(*e.Dest()), geometry.UnwrapPoint(*e.LNext().Dest())
+		cc := circumcenter(a, b, c)
+		center := geometry.NewPoint(cc[0], cc[1])
+		if _, ok := vertexIndex.Get(center); ok {
+			// Can't do any better for this triangle.
+			continue
+		}
+
+		// The circumcenter on the other side of a segment encroaches it.
+		s, err := sd.segmentBetween(e, center)
+		if err != nil {
+			return steiner, err
+		}
+		if s != nil {
+			if err = split(s); err != nil {
+				return steiner, err
+			}
+			triangles = append(triangles, e)
+			continue
+		}
+
+		if err = sd.insertSite(vertexIndex, center); err != nil {
+			return steiner, err
+		}
+		var encroached []*quadedge.Edge
+		spoke, _ := vertexIndex.Get(center)
+		for s := spoke; ; {
+			if r := s.LNext(); r.IsConstrained() && encroaches(r, cc) {
+				encroached = append(encroached, r)
+			}
+			if s = s.ONext(); s == spoke {
+				break
+			}
+		}
+		if len(encroached) == 0 {
+			added(center)
+			continue
+		}
+
+		// Take the circumcenter out, and split the segments instead.
+		err = sd.RemoveSite(vertexIndex, center)
+		if err == ErrConstrainedVertex {
+			// The circumcenter fell on, and split, a segment; keep it,
+			// the segments it encroaches are checked with it's edges.
+			added(center)
+			continue
+		}
+		if err != nil {
+			return steiner, err
+		}
+		for _, s := range encroached {
+			if isDeleted(s) || !s.IsConstrained() {
+				continue
+			}
+			if err = split(s); err != nil {
+				return steiner, err
+			}
+		}
+		triangles = append(triangles, e)
+	}
+}
+
+// isDeleted returns if the edge has been deleted from the subdivision. A
+// deleted edge may have been reused since, it is then a live edge, and is
+// checked like any other.
+func isDeleted(e *quadedge.Edge) bool {
+	return e.ONext() == e && e.Sym().ONext() == e.Sym()
+}
+
+// constrainHull inserts the edges of the convex hull of the points, that
+// are not part of the frame, as constraints.
+func (sd *Subdivision) constrainHull(ctx context.Context, vertexIndex *VertexIndex) error {
+	k := sd.kernel
+	var xy [][2]float64
+	vertexIndex.Each(func(e *quadedge.Edge) {
+		if sd.isFramePoint(*e.Orig()) {
+			return
+		}
+		xy = append(xy, geometry.UnwrapPoint(*e.Orig()))
+	})
+	if len(xy) < 3 {
+		return nil
+	}
+	sort.Sort(cmp.ByXY(xy))
+	pts := make([]geometry.Point, len(xy))
+	for i := range xy {
+		pts[i] = geometry.NewPoint(xy[i][0], xy[i][1])
+	}
+
+	// Andrew's monotone chain, the lower then the upper hull.
+	var hull []geometry.Point
+	for pass := 0; pass < 2; pass++ {
+		start := len(hull)
+		for _, pt := range pts {
+			for len(hull) >= start+2 && k.Orientation(hull[len(hull)-2], hull[len(hull)-1], pt) <= 0 {
+				hull = hull[:len(hull)-1]
+			}
+			hull = append(hull, pt)
+		}
+		// The last point is the first point of the other hull.
+		hull = hull[:len(hull)-1]
+		for i, j := 0, len(pts)-1; i < j; i, j = i+1, j-1 {
+			pts[i], pts[j] = pts[j], pts[i]
+		}
+	}
+
+	for i := range hull {
+		if _, err := sd.constrain(ctx, vertexIndex, hull[i], hull[(i+1)%len(hull)], false); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// isEncroached returns if one of the vertices opposite the edge is in the
+// diametral circle of the edge.
+func (sd *Subdivision) isEncroached(e *quadedge.Edge) bool {
+	for _, apex := range [2]*geometry.Point{e.LNext().Dest(), e.Sym().LNext().Dest()} {
+		if sd.isFramePoint(*apex) {
+			continue
+		}
+		if encroaches(e, geometry.UnwrapPoint(*apex)) {
+			return true
+		}
+	}
+	return false
+}
+
+// encroaches returns if the point is inside of the diametral circle of e.
+func encroaches(e *quadedge.Edge, pt [2]float64) bool {
+	a, b := geometry.UnwrapPoint(*e.Orig()), geometry.UnwrapPoint(*e.Dest())
+	return (a[0]-pt[0])*(b[0]-pt[0])+(a[1]-pt[1])*(b[1]-pt[1]) < 0
+}
+
+// isBadTriangle returns if the triangle on the left of e, not attached to the
+// frame, has an angle or an area outside of the limits.
+func (sd *Subdivision) isBadTriangle(e *quadedge.Edge, opts RefineOptions) bool {
+	if e.LNext().LNext().LNext() != e {
+		return false
+	}
+	pa, pb, pc := *e.Orig(), *e.Dest(), *e.LNext().Dest()
+	if sd.isFramePoint(pa, pb, pc) {
+		return false
+	}
+	a, b, c := geometry.UnwrapPoint(pa), geometry.UnwrapPoint(pb), geometry.UnwrapPoint(pc)
+	area := math.Abs((b[0]-a[0])*(c[1]-a[1])-(b[1]-a[1])*(c[0]-a[0])) / 2
+	if opts.MaxArea > 0 && area > opts.MaxArea {
+		return true
+	}
+	if opts.MinAngle <= 0 {
+		return false
+	}
+	// The smallest angle is opposite the shortest side.
+	sides := [3]float64{
+		math.Hypot(b[0]-a[0], b[1]-a[1]),
+		math.Hypot(c[0]-b[0], c[1]-b[1]),
+		math.Hypot(a[0]-c[0], a[1]-c[1]),
+	}
+	shortest := 0
+	for i := range sides {
+		if sides[i] < sides[shortest] {
+			shortest = i
+		}
+	}
+	other := sides[(shortest+1)%3] * sides[(shortest+2)%3]
+	angle := math.Asin(math.Min(1, 2*area/other)) * 180 / math.Pi
+	return angle < opts.MinAngle
+}
+
+// circumcenter returns the center of the circle through a, b, and c.
+func circumcenter(a, b, c [2]float64) [2]float64 {
+	bx, by := b[0]-a[0], b[1]-a[1]
+	cx, cy := c[0]-a[0], c[1]-a[1]
+	d := 2 * (bx*cy - by*cx)
+	b2, c2 := bx*bx+by*by, cx*cx+cy*cy
+	return [2]float64{
+		a[0] + (cy*b2-by*c2)/d,
+		a[1] + (bx*c2-cx*b2)/d,
+	}
+}
+
+// segmentBetween walks, in a straight line, from the triangle on the left of
+// e to pt, and returns the first segment crossed, or the segment pt is on.
+// If there isn't one nil is returned. If the centroid of the triangle can't
+// be represented inside of it, for the integer backend, any edge with pt on
+// the other side is crossed.
+func (sd *Subdivision) segmentBetween(e *quadedge.Edge, pt geometry.Point) (*quadedge.Edge, error) {
+	k := sd.kernel
+	a, b, c := geometry.UnwrapPoint(*e.Orig()), geometry.UnwrapPoint(*e.Dest()), geometry.UnwrapPoint(*e.LNext().Dest())
+	g := geometry.NewPoint((a[0]+b[0]+c[0])/3, (a[1]+b[1]+c[1])/3)
+	// The faces are clockwise if the frame is.
+	orientation := k.Orientation(*e.Orig(), *e.Dest(), *e.LNext().Dest())
+	straight := true
+	for _, te := range [3]*quadedge.Edge{e, e.LNext(), e.LPrev()} {
+		straight = straight && k.Orientation(*te.Orig(), *te.Dest(), g) == orientation
+	}
+
+	for i := 0; i <= 2*sd.ptcount+6; i++ {
+		edges := [3]*quadedge.Edge{e, e.LNext(), e.LPrev()}
+		var next *quadedge.Edge
+		for _, te := range edges {
+			o, d := *te.Orig(), *te.Dest()
+			if k.Orientation(o, d, pt)*orientation >= 0 {
+				// pt is not on the other side of this edge.
+				continue
+			}
+			if straight && k.Orientation(g, pt, o)*k.Orientation(g, pt, d) > 0 {
+				// The line does not cross this edge.
+				continue
+			}
+			next = te
+			break
+		}
+		if next == nil {
+			// pt is in this triangle.
+			for _, te := range edges {
+				if onEdge(k, pt, te) && te.IsConstrained() {
+					return te, nil
+				}
+			}
+			return nil, nil
+		}
+		if next.IsConstrained() {
+			return next, nil
+		}
+		e = next.Sym()
+	}
+	return nil, ErrAssumptionFailed()
+}
+
+// splitSegment splits the constraint e at it's midpoint. It returns the midpoint
+// and if it was inserted in to the subdivision.
+func (sd *Subdivision) splitSegment(ctx context.Context, vertexIndex *VertexIndex, e *quadedge.Edge) (mid geometry.Point, inserted bool, err error) {
+	a, b := *e.Orig(), *e.Dest()
+	ua, ub := geometry.UnwrapPoint(a), geometry.UnwrapPoint(b)
+	mid = geometry.NewPoint((ua[0]+ub[0])/2, (ua[1]+ub[1])/2)
+	if ptEqual(sd.kernel, mid, &a) || ptEqual(sd.kernel, mid, &b) {
+		return mid, false, ErrSegmentTooShort
+	}
+	inserted, err = sd.splitConstraintAt(ctx, vertexIndex, a, b, mid)
+	return mid, inserted, err
+}
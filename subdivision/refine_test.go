@@ -0,0 +1,127 @@
+package subdivision
+
+import (
+	"context"
+	"math"
+	"strconv"
+	"testing"
+
+	"github.com/gdey/quad-edge/geometry"
+	"github.com/gdey/quad-edge/quadedge"
+)
+
+// minAngle returns the smallest angle, in degrees, of the triangle.
+func minAngle(tri [3]geometry.Point) float64 {
+	min := 180.0
+	for i := range tri {
+		a, b, c := geometry.UnwrapPoint(tri[i]), geometry.UnwrapPoint(tri[(i+1)%3]), geometry.UnwrapPoint(tri[(i+2)%3])
+		ab := math.Atan2(b[1]-a[1], b[0]-a[0])
+		ac := math.Atan2(c[1]-a[1], c[0]-a[0])
+		angle := math.Abs(ab-ac) * 180 / math.Pi
+		if angle > 180 {
+			angle = 360 - angle
+		}
+		min = math.Min(min, angle)
+	}
+	return min
+}
+
+func TestRefine(t *testing.T) {
+	if geometry.Type == "int64" {
+		// The inserted points are truncated to integers, so the
+		// triangles can't be refined past a certain size.
+		t.Skip("refinement needs a floating point backend")
+	}
+	type tcase struct {
+		points      [][2]float64
+		constraints [][2][2]float64
+		opts        RefineOptions
+	}
+
+	fn := func(tc tcase) func(*testing.T) {
+		return func(t *testing.T) {
+			ctx := context.Background()
+			pts := append([][2]float64(nil), tc.points...)
+			for _, c := range tc.constraints {
+				pts = append(pts, c[0], c[1])
+			}
+			sd := insertPoints(t, pts)
+			vx := sd.VertexIndex()
+			for _, c := range tc.constraints {
+				err := sd.InsertConstraint(ctx, vx, geometry.NewPoint(c[0][0], c[0][1]), geometry.NewPoint(c[1][0], c[1][1]))
+				if err != nil {
+					t.Fatalf("insert constraint, expected nil got %v", err)
+				}
+			}
+
+			steiner, err := sd.Refine(ctx, vx, tc.opts)
+			if err != nil {
+				t.Fatalf("refine, expected nil got %v", err)
+			}
+			if tc.opts.MaxSteiner > 0 && len(steiner) > tc.opts.MaxSteiner {
+				t.Errorf("steiner points, expected at most %v got %v", tc.opts.MaxSteiner, len(steiner))
+			}
+			if tc.opts.MaxSteiner > 0 {
+				// The limits don't hold if refinement was stopped.
+				return
+			}
+
+			tris, err := sd.Triangles(false)
+			if err != nil {
+				t.Fatalf("triangles, expected nil got %v", err)
+			}
+			for _, tri := range tris {
+				if angle := minAngle(tri); tc.opts.MinAngle > 0 && angle < tc.opts.MinAngle-1e-9 {
+					t.Errorf("triangle %v, expected min angle >= %v got %v", tri, tc.opts.MinAngle, angle)
+				}
+				a, b, c := geometry.UnwrapPoint(tri[0]), geometry.UnwrapPoint(tri[1]), geometry.UnwrapPoint(tri[2])
+				area := math.Abs((b[0]-a[0])*(c[1]-a[1])-(b[1]-a[1])*(c[0]-a[0])) / 2
+				if tc.opts.MaxArea > 0 && area > tc.opts.MaxArea {
+					t.Errorf("triangle %v, expected area <= %v got %v", tri, tc.opts.MaxArea, area)
+				}
+			}
+
+			for _, c := range tc.constraints {
+				if !isRecovered(vx, c) {
+					t.Errorf("constraint %v, expected to be edges of the subdivision", c)
+				}
+			}
+
+			// The vertex index should have been kept in sync.
+			vx.Each(func(e *quadedge.Edge) {
+				if got, _ := vx.Get(*e.Orig()); got != e {
+					t.Errorf("vertex index %v, expected edge from it got %v", *e.Orig(), e.AsGeomLine())
+				}
+			})
+		}
+	}
+
+	square := [][2]float64{{0, 0}, {1000, 0}, {1000, 1000}, {0, 1000}}
+	tests := []tcase{
+		{
+			points: append(square, [2]float64{500, 1}, [2]float64{480, 3}, [2]float64{900, 700}),
+			opts:   RefineOptions{MinAngle: 20},
+		},
+		{
+			points:      append(square, [2]float64{200, 210}, [2]float64{700, 650}),
+			constraints: [][2][2]float64{{{100, 500}, {900, 520}}},
+			opts:        RefineOptions{MinAngle: 20, MaxArea: 5000},
+		},
+		{
+			points: square,
+			opts:   RefineOptions{MaxArea: 10000},
+		},
+		{
+			points:      randomPoints(300, 1),
+			constraints: [][2][2]float64{{{1 << 18, 1 << 18}, {3 << 18, 1 << 19}}},
+			opts:        RefineOptions{MinAngle: 25},
+		},
+		{
+			points: square,
+			opts:   RefineOptions{MaxArea: 100, MaxSteiner: 50},
+		},
+	}
+	for i, tc := range tests {
+		t.Run(strconv.Itoa(i), fn(tc))
+	}
+}
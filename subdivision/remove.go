@@ -0,0 +1,171 @@
+package subdivision
+
+import (
+	"sort"
+
+	"github.com/gdey/quad-edge/geometry"
+	"github.com/gdey/quad-edge/quadedge"
+)
+
+// RemoveSite will remove the point x from a subdivision representing a
+// Delaunay triangulation, and re-triangulate the hole left behind so that
+// the result is still a Delaunay triangulation. The vertexIndex, if not nil,
+// is kept in sync with the subdivision.
+//
+// The hole is filled by clipping ears, as described by Devillers (1999),
+// "On Deletion in Delaunay Triangulations". The ears are tried in order of
+// the power of x with respect to their circumcircle, and each ear is checked
+// with the exact incircle predicate before it is clipped.
+//
+// A vertex of a constraint can not be removed, ErrConstrainedVertex is
+// returned; remove the constraints with RemoveConstraint first. If an error
+// is returned the subdivision is not changed.
+func (sd *Subdivision) RemoveSite(vertexIndex *VertexIndex, x geometry.Point) error {
+	if sd.isFramePoint(x) {
+		return ErrFrameVertex
+	}
+
+	var (
+		e  *quadedge.Edge
+		ok bool
+	)
+	if vertexIndex != nil {
+		e, ok = vertexIndex.Get(x)
+	} else {
+		var loc Location
+		loc, ok = sd.Locate(x)
+		ok = ok && loc.Type == ON_VERTEX
+		e = loc.Edge
+	}
+	if !ok || e == nil {
+		return ErrVertexNotFound
+	}
+
+	// Collect the edges of the polygon around x, with the hole on their left.
+	var spokes, ring []*quadedge.Edge
+	spoke := e
+	for {
+		if spoke.IsConstrained() {
+			return ErrConstrainedVertex
+		}
+		spokes = append(spokes, spoke)
+		ring = append(ring, spoke.LNext())
+		spoke = spoke.ONext()
+		if spoke == e {
+			break
+		}
+	}
+
+	// Pick the ears before changing anything, so a failure leaves the
+	// subdivision as it was.
+	pts := make([]geometry.Point, len(ring))
+	for i := range ring {
+		pts[i] = *ring[i].Orig()
+	}
+	ears, err := sd.earClipping(pts, x)
+	if err != nil {
+		return err
+	}
+
+	vertex := e.Orig()
+	for _, spoke := range spokes {
+		if vertexIndex != nil {
+			vertexIndex.Remove(spoke)
+		}
+		sd.pool.Delete(spoke)
+	}
+	sd.pool.FreePoint(vertex)
+	sd.startingEdge = ring[0]
+	sd.grid.Add(ring[0])
+	sd.ptcount--
+
+	for _, i := range ears {
+		j := (i + 1) % len(ring)
+		ne := sd.pool.Connect(ring[j], ring[i])
+		if vertexIndex != nil {
+			vertexIndex.Add(ne)
+		}
+		// ne.Sym() replaces the two edges of the ear in the ring.
+		ring[i] = ne.Sym()
+		ring = append(ring[:j], ring[j+1:]...)
+	}
+	return nil
+}
+
+// earClipping returns the ears to clip, in order, to triangulate the hole
+// left by removing x; ring are the points of the hole counterclockwise. Ear
+// i is made up of ring[i], ring[i+1] and ring[i+2], clipping it removes
+// ring[i+1] from the ring.
+func (sd *Subdivision) earClipping(ring []geometry.Point, x geometry.Point) (ears []int, err error) {
+	ring = append([]geometry.Point(nil), ring...)
+	ux := geometry.UnwrapPoint(x)
+	for len(ring) > 3 {
+		candidates := make([]int, 0, len(ring))
+		powers := make([]float64, len(ring))
+		for i := range ring {
+			a, b, c := earPoints(ring, i)
+			if sd.kernel.Orientation(a, b, c) <= 0 {
+				// reflex or flat, not an ear
+				continue
+			}
+			candidates = append(candidates, i)
+			powers[i] = power(
+				geometry.UnwrapPoint(a),
+				geometry.UnwrapPoint(b),
+				geometry.UnwrapPoint(c),
+				ux,
+			)
+		}
+		sort.SliceStable(candidates, func(i, j int) bool { return powers[candidates[i]] < powers[candidates[j]] })
+
+		clipped := false
+		for _, i := range candidates {
+			if !sd.isDelaunayEar(ring, i) {
+				continue
+			}
+			j := (i + 1) % len(ring)
+			ears = append(ears, i)
+			ring = append(ring[:j], ring[j+1:]...)
+			clipped = true
+			break
+		}
+		if !clipped {
+			return nil, ErrAssumptionFailed()
+		}
+	}
+	return ears, nil
+}
+
+// earPoints returns the points of ear i of the ring.
+func earPoints(ring []geometry.Point, i int) (a, b, c geometry.Point) {
+	return ring[i], ring[(i+1)%len(ring)], ring[(i+2)%len(ring)]
+}
+
+// isDelaunayEar returns if none of the other points of the ring are in
+// the circumcircle of ear i.
+func (sd *Subdivision) isDelaunayEar(ring []geometry.Point, i int) bool {
+	a, b, c := earPoints(ring, i)
+	for k := range ring {
+		if k == i || k == (i+1)%len(ring) || k == (i+2)%len(ring) {
+			continue
+		}
+		if geometry.KernelPerturbedInCircle(sd.kernel, a, b, c, ring[k]) {
+			return false
+		}
+	}
+	return true
+}
+
+// power returns the power of the point x with respect to the circumcircle
+// of the counterclockwise triangle a, b, c. It is negative if x is inside
+// the circle.
+func power(a, b, c, x [2]float64) float64 {
+	ax, ay := a[0]-x[0], a[1]-x[1]
+	bx, by := b[0]-x[0], b[1]-x[1]
+	cx, cy := c[0]-x[0], c[1]-x[1]
+	det := (ax*ax+ay*ay)*(bx*cy-by*cx) +
+		(bx*bx+by*by)*(cx*ay-cy*ax) +
+		(cx*cx+cy*cy)*(ax*by-ay*bx)
+	area := (bx-ax)*(cy-ay) - (by-ay)*(cx-ax)
+	return -det / area
+}
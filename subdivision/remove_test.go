@@ -0,0 +1,170 @@
+package subdivision
+
+import (
+	"context"
+	"reflect"
+	"strconv"
+	"testing"
+
+	"github.com/gdey/quad-edge/geometry"
+	"github.com/gdey/quad-edge/quadedge"
+)
+
+// triangleSet returns the triangles of the subdivision, the points of
+// each triangle are rotated so that the smallest is first.
+func triangleSet(t *testing.T, sd *Subdivision) map[[3][2]float64]bool {
+	tris, err := sd.Triangles(true)
+	if err != nil {
+		t.Fatalf("error, expected nil got %v", err)
+	}
+	set := make(map[[3][2]float64]bool, len(tris))
+	for _, tri := range tris {
+		// rotate the smallest point to the front
+		m := 0
+		for i := 1; i < 3; i++ {
+			a, b := geometry.UnwrapPoint(tri[m]), geometry.UnwrapPoint(tri[i])
+			if b[0] < a[0] || (b[0] == a[0] && b[1] < a[1]) {
+				m = i
+			}
+		}
+		set[[3][2]float64{
+			geometry.UnwrapPoint(tri[m]),
+			geometry.UnwrapPoint(tri[(m+1)%3]),
+			geometry.UnwrapPoint(tri[(m+2)%3]),
+		}] = true
+	}
+	return set
+}
+
+func TestRemoveSite(t *testing.T) {
+	type tcase struct {
+		points [][2]float64
+		remove [][2]float64
+		index  bool
+	}
+
+	fn := func(tc tcase) func(*testing.T) {
+		return func(t *testing.T) {
+			all := append(append([][2]float64(nil), tc.points...), tc.remove...)
+			tri := geometry.TriangleContaining(all...)
+			newSD := func(pts [][2]float64) *Subdivision {
+				sd := New(
+					geometry.NewPoint(tri[0][0], tri[0][1]),
+					geometry.NewPoint(tri[1][0], tri[1][1]),
+					geometry.NewPoint(tri[2][0], tri[2][1]),
+				)
+				for _, pt := range pts {
+					if !sd.InsertSite(geometry.NewPoint(pt[0], pt[1])) {
+						t.Fatalf("failed to insert point %v", pt)
+					}
+				}
+				return sd
+			}
+
+			sd := newSD(all)
+			var vx *VertexIndex
+			if tc.index {
+				vx = sd.VertexIndex()
+			}
+			for _, pt := range tc.remove {
+				if err := sd.RemoveSite(vx, geometry.NewPoint(pt[0], pt[1])); err != nil {
+					t.Fatalf("remove %v, expected nil got %v", pt, err)
+				}
+			}
+
+			expected := triangleSet(t, newSD(tc.points))
+			got := triangleSet(t, sd)
+			if len(got) != len(expected) {
+				t.Errorf("number of triangles, expected %v got %v", len(expected), len(got))
+			}
+			for tri := range expected {
+				if !got[tri] {
+					t.Errorf("triangle, expected %v got nothing", tri)
+				}
+			}
+
+			if tc.index {
+				expectedVx := sd.VertexIndex()
+				if vx.Len() != expectedVx.Len() {
+					t.Errorf("vertex index size, expected %v got %v", expectedVx.Len(), vx.Len())
+				}
+				vx.Each(func(e *quadedge.Edge) {
+					if got, _ := vx.Get(*e.Orig()); got != e {
+						t.Errorf("vertex index %v, expected edge from it got %v", *e.Orig(), e.AsGeomLine())
+					}
+				})
+			}
+		}
+	}
+
+	grid := func(n int) (pts [][2]float64) {
+		for x := 0; x < n; x++ {
+			for y := 0; y < n; y++ {
+				pts = append(pts, [2]float64{float64(x * 10), float64(y * 10)})
+			}
+		}
+		return pts
+	}
+	random := randomPoints(500, 2)
+
+	tests := []tcase{
+		{
+			points: [][2]float64{{0, 0}, {10, 0}, {0, 10}},
+			remove: [][2]float64{{3, 3}},
+		},
+		{
+			points: grid(4)[1:],
+			remove: grid(4)[:1],
+			index:  true,
+		},
+		{ // cocircular points around the center of the grid
+			points: append(grid(5)[:12], grid(5)[13:]...),
+			remove: [][2]float64{{20, 20}},
+		},
+		{
+			points: random[100:],
+			remove: random[:100],
+		},
+		{
+			points: random[100:],
+			remove: random[:100],
+			index:  true,
+		},
+	}
+	for i, tc := range tests {
+		t.Run(strconv.Itoa(i), fn(tc))
+	}
+}
+
+func TestRemoveSiteErrors(t *testing.T) {
+	pts := [][2]float64{{0, 0}, {10, 0}, {0, 10}, {10, 10}}
+	sd := insertPoints(t, pts)
+	if err := sd.RemoveSite(nil, geometry.NewPoint(5, 6)); err != ErrVertexNotFound {
+		t.Errorf("error, expected %v got %v", ErrVertexNotFound, err)
+	}
+	if err := sd.RemoveSite(nil, sd.frame[0]); err != ErrFrameVertex {
+		t.Errorf("error, expected %v got %v", ErrFrameVertex, err)
+	}
+
+	// The vertices of a constraint can't be removed, and the subdivision
+	// is left as it was.
+	pts = [][2]float64{{0, 0}, {20, 0}, {0, 20}, {20, 20}, {10, 10}, {5, 15}}
+	sd = insertPoints(t, pts)
+	segment := [2][2]float64{{0, 0}, {20, 20}}
+	if err := sd.InsertConstraint(context.Background(), nil, geometry.NewPoint(0, 0), geometry.NewPoint(20, 20)); err != nil {
+		t.Fatalf("insert constraint, expected nil got %v", err)
+	}
+	expected := triangleSet(t, sd)
+	expectedLength, _ := constrainedLength(sd, segment)
+	for _, pt := range [][2]float64{{20, 20}, {10, 10}} {
+		if err := sd.RemoveSite(nil, geometry.NewPoint(pt[0], pt[1])); err != ErrConstrainedVertex {
+			t.Errorf("remove %v, expected %v got %v", pt, ErrConstrainedVertex, err)
+		}
+	}
+	if length, _ := constrainedLength(sd, segment); length != expectedLength {
+		t.Errorf("constrained length, expected %v got %v", expectedLength, length)
+	}
+	if got := triangleSet(t, sd); !reflect.DeepEqual(got, expected) {
+		t.Errorf("triangles, expected %v got %v", expected, got)
+	}
+}
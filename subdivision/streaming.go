@@ -0,0 +1,391 @@
+package subdivision
+
+import (
+	"container/heap"
+	"context"
+	"io"
+	"math"
+	"math/big"
+
+	"github.com/gdey/quad-edge/geometry"
+	"github.com/gdey/quad-edge/quadedge"
+	"github.com/go-spatial/geom"
+	"github.com/go-spatial/geom/cmp"
+)
+
+// PointReader is a source of points, for a Streaming triangulation.
+type PointReader interface {
+	// ReadPoint returns the next point, or io.EOF once there are no
+	// more points.
+	ReadPoint() ([2]float64, error)
+}
+
+// Streaming is a Delaunay triangulation of a stream of points, that only
+// keeps the part of the triangulation that can still change in memory. The
+// points need to be sorted, by x then y. Once a triangle's circumcircle is
+// entirely to the left of the last point inserted, no point still to come
+// can be in it, so it is final; it is passed to the emit function, and the
+// edges between it and other final triangles are deleted. A vertex with all
+// of it's edges deleted is freed. The edges between final triangles and the
+// rest of the triangulation are marked as constraints, so they are not
+// swapped.
+//
+// The frame is built around the extent. The triangles emitted are the
+// triangles, not attached to the frame, of the Delaunay triangulation of the
+// points and the frame; so when the extent is the bounding box of the points
+// they are the ones NewForPoints would give, otherwise the triangles along
+// the convex hull of the points can differ. See Isenburg, Liu, Shewchuk and
+// Snoeyink (2006), "Streaming Computation of Delaunay Triangulations", this
+// uses the sort order of the points, not spatial finalization tags, to
+// decide when triangles are final.
+type Streaming struct {
+	sd     *Subdivision
+	extent geom.Extent
+	emit   func(tri [3]geometry.Point) error
+	// last is an edge from the last point inserted.
+	last    *quadedge.Edge
+	lastPt  [2]float64
+	started bool
+	// queue holds the triangles that are not final yet.
+	queue triangleQueue
+	// final holds the edges with a final triangle, or a hole left by
+	// deleting final triangles, on their left.
+	final map[*quadedge.Edge]bool
+}
+
+// NewStreaming returns a Streaming triangulation for points inside of the
+// extent, that calls emit with each triangle once it is final.
+func NewStreaming(extent geom.Extent, emit func(tri [3]geometry.Point) error, opts ...Option) *Streaming {
+	tri := geometry.TriangleContaining(
+		[2]float64{extent.MinX(), extent.MinY()},
+		[2]float64{extent.MaxX(), extent.MaxY()},
+	)
+	sd := New(
+		geometry.NewPoint(tri[0][0], tri[0][1]),
+		geometry.NewPoint(tri[1][0], tri[1][1]),
+		geometry.NewPoint(tri[2][0], tri[2][1]),
+		opts...,
+	)
+	return &Streaming{
+		sd:     sd,
+		extent: extent,
+		emit:   emit,
+		last:   sd.startingEdge,
+		final:  make(map[*quadedge.Edge]bool),
+	}
+}
+
+// Stream reads all of the points from r, which need to be sorted by x then y
+// and inside of the extent, and calls emit with the triangles of their
+// Delaunay triangulation, as they are final.
+func Stream(ctx context.Context, extent geom.Extent, r PointReader, emit func(tri [3]geometry.Point) error, opts ...Option) error {
+	st := NewStreaming(extent, emit, opts...)
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		pt, err := r.ReadPoint()
+		if err == io.EOF {
+			return st.Flush()
+		}
+		if err != nil {
+			return err
+		}
+		if err = st.Insert(pt); err != nil {
+			return err
+		}
+	}
+}
+
+// Insert inserts the point, and emits the triangles that are now final. The
+// point can not come before the last point inserted, by x then y, and needs
+// to be inside of the extent.
+func (st *Streaming) Insert(pt [2]float64) error {
+	if st.started && cmp.PointLess(pt, st.lastPt) {
+		return ErrPointsNotSorted
+	}
+	if pt[0] < st.extent.MinX() || pt[0] > st.extent.MaxX() || pt[1] < st.extent.MinY() || pt[1] > st.extent.MaxY() {
+		return ErrOutsideExtent
+	}
+	st.started, st.lastPt = true, pt
+
+	x := geometry.NewPoint(pt[0], pt[1])
+	e, ok := st.locate(x)
+	if !ok {
+		return ErrSiteNotInserted
+	}
+	switch {
+	case ptEqual(st.sd.kernel, x, e.Orig()):
+		st.last = e
+		return nil
+	case ptEqual(st.sd.kernel, x, e.Dest()):
+		st.last = e.Sym()
+		return nil
+	}
+	st.sd.ptcount++
+	if !st.sd.insertSiteAt(x, e, nil) {
+		return ErrSiteNotInserted
+	}
+	// The new triangles are the ones around the point.
+	st.last = st.sd.startingEdge.Sym()
+	for s := st.last; ; {
+		st.push(s)
+		if s = s.ONext(); s == st.last {
+			break
+		}
+	}
+
+	// The queue is ordered by the rounded right of the circumcircles, so
+	// each triangle is checked exactly before it is final.
+	sweep := geometry.UnwrapPoint(x)[0]
+	for len(st.queue) > 0 && st.queue[0].right < sweep {
+		t := st.queue[0]
+		if !st.isLive(t) {
+			heap.Pop(&st.queue)
+			continue
+		}
+		if !circleLeftOf(t.verts, sweep) {
+			break
+		}
+		heap.Pop(&st.queue)
+		if err := st.finalize(t.edge); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Flush emits the triangles that are left. Nothing more should be inserted
+// afterwards.
+func (st *Streaming) Flush() error {
+	for len(st.queue) > 0 {
+		t := heap.Pop(&st.queue).(queuedTriangle)
+		if !st.isLive(t) {
+			continue
+		}
+		if err := st.emitTriangle(t.edge); err != nil {
+			return err
+		}
+		for _, fe := range faceEdges(t.edge) {
+			st.final[fe] = true
+		}
+	}
+	return nil
+}
+
+// push queues the triangle on the left of e, unless it is attached to the
+// frame; those are never final.
+func (st *Streaming) push(e *quadedge.Edge) {
+	pa, pb, pc := e.Orig(), e.Dest(), e.LNext().Dest()
+	if st.sd.isFramePoint(*pa, *pb, *pc) {
+		return
+	}
+	a, b, c := geometry.UnwrapPoint(*pa), geometry.UnwrapPoint(*pb), geometry.UnwrapPoint(*pc)
+	cc := circumcenter(a, b, c)
+	right := cc[0] + math.Hypot(a[0]-cc[0], a[1]-cc[1])
+	if math.IsNaN(right) || math.IsInf(right, 0) {
+		// Collinear points, the triangle is only final at the end.
+		right = math.Inf(1)
+	}
+	heap.Push(&st.queue, queuedTriangle{
+		right: right,
+		edge:  e,
+		verts: [3]geometry.Point{*pa, *pb, *pc},
+	})
+}
+
+// isLive returns if the queued triangle is still a triangle of the
+// triangulation, that has not been emitted. The edges, and vertices, of
+// final triangles are reused; but a triangle with the same vertices is the
+// same triangle.
+func (st *Streaming) isLive(t queuedTriangle) bool {
+	e := t.edge
+	if st.final[e] || e.LNext().LNext().LNext() != e {
+		return false
+	}
+	k := st.sd.kernel
+	return ptEqual(k, t.verts[0], e.Orig()) && ptEqual(k, t.verts[1], e.Dest()) && ptEqual(k, t.verts[2], e.LNext().Dest())
+}
+
+// circleLeftOf returns if the circumcircle of the counterclockwise triangle
+// is entirely to the left of the vertical line at x; computed exactly.
+func circleLeftOf(tri [3]geometry.Point, x float64) bool {
+	var f [7]*big.Rat
+	for i, v := range []float64{
+		geometry.UnwrapPoint(tri[0])[0], geometry.UnwrapPoint(tri[0])[1],
+		geometry.UnwrapPoint(tri[1])[0], geometry.UnwrapPoint(tri[1])[1],
+		geometry.UnwrapPoint(tri[2])[0], geometry.UnwrapPoint(tri[2])[1],
+		x,
+	} {
+		if f[i] = new(big.Rat).SetFloat64(v); f[i] == nil {
+			// Not finite.
+			return false
+		}
+	}
+	add := func(a, b *big.Rat) *big.Rat { return new(big.Rat).Add(a, b) }
+	sub := func(a, b *big.Rat) *big.Rat { return new(big.Rat).Sub(a, b) }
+	mul := func(a, b *big.Rat) *big.Rat { return new(big.Rat).Mul(a, b) }
+
+	// The circumcenter is a + (px, py) / d, see circumcenter.
+	bx, by := sub(f[2], f[0]), sub(f[3], f[1])
+	cx, cy := sub(f[4], f[0]), sub(f[5], f[1])
+	b2, c2 := add(mul(bx, bx), mul(by, by)), add(mul(cx, cx), mul(cy, cy))
+	d := sub(mul(bx, cy), mul(by, cx))
+	d = add(d, d)
+	if d.Sign() <= 0 {
+		return false
+	}
+	px := sub(mul(cy, b2), mul(by, c2))
+	py := sub(mul(bx, c2), mul(cx, b2))
+
+	// x - center > radius, multiplied through by d.
+	g := sub(mul(sub(f[6], f[0]), d), px)
+	if g.Sign() <= 0 {
+		return false
+	}
+	return mul(g, g).Cmp(add(mul(px, px), mul(py, py))) > 0
+}
+
+func (st *Streaming) emitTriangle(e *quadedge.Edge) error {
+	if st.emit == nil {
+		return nil
+	}
+	return st.emit([3]geometry.Point{*e.Orig(), *e.Dest(), *e.LNext().Dest()})
+}
+
+// finalize emits the triangle on the left of e, and deletes the edges
+// between it and final triangles.
+func (st *Streaming) finalize(e *quadedge.Edge) error {
+	if err := st.emitTriangle(e); err != nil {
+		return err
+	}
+	edges := faceEdges(e)
+	for _, fe := range edges {
+		st.final[fe] = true
+	}
+	for _, fe := range edges {
+		sym := fe.Sym()
+		if !st.final[sym] {
+			fe.SetConstrained(true)
+			continue
+		}
+		delete(st.final, fe)
+		delete(st.final, sym)
+		// The last edge of a vertex, it's triangles are all final.
+		var freed []*geometry.Point
+		if fe.ONext() == fe {
+			freed = append(freed, fe.Orig())
+		}
+		if sym.ONext() == sym {
+			freed = append(freed, sym.Orig())
+		}
+		st.sd.pool.Delete(fe)
+		for _, v := range freed {
+			st.sd.pool.FreePoint(v)
+			st.sd.ptcount--
+		}
+	}
+	return nil
+}
+
+// locate walks, in a straight line, from the last point inserted to x, and
+// returns an edge with x on it, or in the face on it's left. As the points
+// are sorted the line stays to the right of the final triangles. The faces of
+// the triangulation have a positive orientation, the outside of the frame a
+// negative one.
+func (st *Streaming) locate(x geometry.Point) (*quadedge.Edge, bool) {
+	k := st.sd.kernel
+	v := st.last
+	for steps := 0; steps <= 2*st.sd.ptcount+6; steps++ {
+		if ptEqual(k, x, v.Orig()) {
+			return v, true
+		}
+		vpt := *v.Orig()
+
+		// Find the triangle around v that the line starts in.
+		var e *quadedge.Edge
+		for s := v; ; {
+			b, c := *s.Dest(), *s.LNext().Dest()
+			if k.Orientation(vpt, b, c) > 0 && k.Orientation(vpt, b, x) >= 0 && k.Orientation(vpt, x, c) >= 0 {
+				e = s
+				break
+			}
+			if s = s.ONext(); s == v {
+				return nil, false
+			}
+		}
+		b, c := *e.Dest(), *e.LNext().Dest()
+		if k.Orientation(b, c, x) >= 0 {
+			return faceEdge(k, e, x), true
+		}
+		switch {
+		case k.Orientation(vpt, b, x) == 0:
+			// The line goes through the vertex.
+			v = e.Sym()
+			continue
+		case k.Orientation(vpt, x, c) == 0:
+			v = e.LPrev()
+			continue
+		}
+
+		// Walk across the triangles crossed by the line, until x is in
+		// one, or the line goes through a vertex. a is to the left of the
+		// line, and b to the right.
+		e = e.LNext().Sym()
+		for ; steps <= 2*st.sd.ptcount+6; steps++ {
+			if e.LNext().LNext().LNext() != e {
+				return nil, false
+			}
+			a, b, w := *e.Orig(), *e.Dest(), *e.LNext().Dest()
+			if k.Orientation(b, w, x) >= 0 && k.Orientation(w, a, x) >= 0 {
+				return faceEdge(k, e, x), true
+			}
+			ow := k.Orientation(vpt, x, w)
+			if ow == 0 {
+				v = e.LPrev()
+				break
+			}
+			if ow > 0 {
+				// w is to the left of the line, leave through b, w.
+				e = e.LNext().Sym()
+			} else {
+				e = e.LPrev().Sym()
+			}
+		}
+	}
+	return nil, false
+}
+
+// faceEdge returns the edge of the face on the left of e that x is on, or
+// e if x is inside of the face.
+func faceEdge(k geometry.Kernel, e *quadedge.Edge, x geometry.Point) *quadedge.Edge {
+	for _, fe := range faceEdges(e) {
+		if ptEqual(k, x, fe.Orig()) || onEdge(k, x, fe) {
+			return fe
+		}
+	}
+	return e
+}
+
+// queuedTriangle is the triangle on the left of edge, with the vertices it
+// had when it was queued; and the right most x of it's circumcircle.
+type queuedTriangle struct {
+	right float64
+	edge  *quadedge.Edge
+	verts [3]geometry.Point
+}
+
+// triangleQueue is a min heap of triangles, by the right of their
+// circumcircles.
+type triangleQueue []queuedTriangle
+
+func (q triangleQueue) Len() int            { return len(q) }
+func (q triangleQueue) Less(i, j int) bool  { return q[i].right < q[j].right }
+func (q triangleQueue) Swap(i, j int)       { q[i], q[j] = q[j], q[i] }
+func (q *triangleQueue) Push(x interface{}) { *q = append(*q, x.(queuedTriangle)) }
+func (q *triangleQueue) Pop() interface{} {
+	old := *q
+	t := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return t
+}
@@ -0,0 +1,168 @@
+package subdivision
+
+import (
+	"context"
+	"io"
+	"math"
+	"sort"
+	"testing"
+
+	"github.com/gdey/quad-edge/geometry"
+	"github.com/go-spatial/geom"
+	"github.com/go-spatial/geom/cmp"
+)
+
+// sliceReader is a PointReader for a slice of points.
+type sliceReader [][2]float64
+
+func (r *sliceReader) ReadPoint() ([2]float64, error) {
+	if len(*r) == 0 {
+		return [2]float64{}, io.EOF
+	}
+	pt := (*r)[0]
+	*r = (*r)[1:]
+	return pt, nil
+}
+
+// triangleKey returns the vertices of the triangle in order, so triangles
+// can be compared regardless of their orientation.
+func triangleKey(tri [3]geometry.Point) [3][2]float64 {
+	key := [3][2]float64{geometry.UnwrapPoint(tri[0]), geometry.UnwrapPoint(tri[1]), geometry.UnwrapPoint(tri[2])}
+	sort.Slice(key[:], func(i, j int) bool { return cmp.PointLess(key[i], key[j]) })
+	return key
+}
+
+func TestStream(t *testing.T) {
+	type tcase struct {
+		points [][2]float64
+	}
+	grid := func(size int) (pts [][2]float64) {
+		for x := 0; x <= size; x++ {
+			for y := 0; y <= size; y++ {
+				pts = append(pts, [2]float64{float64(x * 8), float64(y * 8)})
+			}
+		}
+		return pts
+	}
+	fn := func(tc tcase) func(*testing.T) {
+		return func(t *testing.T) {
+			pts := append([][2]float64(nil), tc.points...)
+			sort.Sort(cmp.ByXY(pts))
+			extent := geom.Extent{pts[0][0], pts[0][1], pts[0][0], pts[0][1]}
+			for _, pt := range pts {
+				extent = geom.Extent{
+					math.Min(extent[0], pt[0]), math.Min(extent[1], pt[1]),
+					math.Max(extent[2], pt[0]), math.Max(extent[3], pt[1]),
+				}
+			}
+
+			expected := make(map[[3][2]float64]bool)
+			tris, err := NewForPoints(context.Background(), append([][2]float64(nil), pts...)).Triangles(false)
+			if err != nil {
+				t.Fatalf("triangles, expected nil got %v", err)
+			}
+			for _, tri := range tris {
+				expected[triangleKey(tri)] = true
+			}
+
+			got := make(map[[3][2]float64]bool)
+			r := sliceReader(pts)
+			err = Stream(context.Background(), extent, &r, func(tri [3]geometry.Point) error {
+				key := triangleKey(tri)
+				if got[key] {
+					t.Errorf("triangle %v emitted more than once", key)
+				}
+				got[key] = true
+				return nil
+			})
+			if err != nil {
+				t.Fatalf("stream, expected nil got %v", err)
+			}
+			if len(got) != len(expected) {
+				t.Errorf("number of triangles, expected %v got %v", len(expected), len(got))
+			}
+			for key := range expected {
+				if !got[key] {
+					t.Errorf("triangle %v, expected to be emitted", key)
+				}
+			}
+		}
+	}
+	tests := map[string]tcase{
+		"random":  {points: randomPoints(2000, 3)},
+		"grid":    {points: grid(12)},
+		"line":    {points: [][2]float64{{0, 0}, {10, 10}, {20, 20}, {20, 0}}},
+		"repeats": {points: [][2]float64{{0, 0}, {0, 0}, {10, 0}, {10, 0}, {5, 10}}},
+	}
+	for name, tc := range tests {
+		t.Run(name, fn(tc))
+	}
+}
+
+func TestStreamingInsert(t *testing.T) {
+	st := NewStreaming(geom.Extent{0, 0, 10, 10}, nil)
+	if err := st.Insert([2]float64{5, 5}); err != nil {
+		t.Fatalf("insert, expected nil got %v", err)
+	}
+	if err := st.Insert([2]float64{4, 5}); err != ErrPointsNotSorted {
+		t.Errorf("insert before the last point, expected %v got %v", ErrPointsNotSorted, err)
+	}
+	if err := st.Insert([2]float64{11, 5}); err != ErrOutsideExtent {
+		t.Errorf("insert outside, expected %v got %v", ErrOutsideExtent, err)
+	}
+
+	// Triangles far enough to the left are final before the end.
+	var count int
+	st = NewStreaming(geom.Extent{0, 0, 100, 10}, func([3]geometry.Point) error {
+		count++
+		return nil
+	})
+	for x := 0; x <= 100; x += 10 {
+		for _, y := range []float64{0, 10} {
+			if err := st.Insert([2]float64{float64(x), y}); err != nil {
+				t.Fatalf("insert, expected nil got %v", err)
+			}
+		}
+	}
+	if count == 0 {
+		t.Errorf("triangles before flush, expected some got none")
+	}
+	if err := st.Flush(); err != nil {
+		t.Fatalf("flush, expected nil got %v", err)
+	}
+	if count != 20 {
+		t.Errorf("number of triangles, expected 20 got %v", count)
+	}
+
+	// The vertices inside of the final triangles are freed.
+	st = NewStreaming(geom.Extent{0, 0, 100, 30}, nil)
+	inserted := 3
+	for x := 0; x <= 100; x += 10 {
+		for y := 0; y <= 30; y += 10 {
+			if err := st.Insert([2]float64{float64(x), float64(y)}); err != nil {
+				t.Fatalf("insert, expected nil got %v", err)
+			}
+			inserted++
+		}
+	}
+	if st.sd.ptcount >= inserted {
+		t.Errorf("vertices, expected less than %v got %v", inserted, st.sd.ptcount)
+	}
+}
+
+func TestCircleLeftOf(t *testing.T) {
+	tri := [3]geometry.Point{geometry.NewPoint(0, 0), geometry.NewPoint(1, 0), geometry.NewPoint(0, 1)}
+	// The right of the circle is 0.5 + √0.5, which rounds down.
+	right := 0.5 + math.Hypot(0.5, 0.5)
+	if circleLeftOf(tri, right) {
+		t.Errorf("circle left of %v, expected false got true", right)
+	}
+	if next := math.Nextafter(right, 2); !circleLeftOf(tri, next) {
+		t.Errorf("circle left of %v, expected true got false", next)
+	}
+	// Collinear points don't have a circumcircle.
+	line := [3]geometry.Point{geometry.NewPoint(0, 0), geometry.NewPoint(1, 1), geometry.NewPoint(2, 2)}
+	if circleLeftOf(line, 10) {
+		t.Errorf("collinear circle left of 10, expected false got true")
+	}
+}
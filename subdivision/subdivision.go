@@ -3,7 +3,6 @@ package subdivision
 import (
 	"context"
 	"errors"
-	"fmt"
 	"log"
 	"sort"
 
@@ -20,50 +19,148 @@ var (
 	ErrCancel           = errors.New("canceled walk")
 	ErrCoincidentEdges  = errors.New("coincident edges")
 	ErrDidNotFindToFrom = errors.New("did not find to and from edge")
+	ErrVertexNotFound   = errors.New("vertex not found")
+	ErrFrameVertex      = errors.New("can not remove a frame vertex")
+	// ErrIntersectingConstraints is returned when a constraint crosses
+	// a constraint already in the subdivision.
+	ErrIntersectingConstraints = errors.New("constraint intersects another constraint")
+	ErrConstraintNotInserted   = errors.New("failed to insert constraint")
+	// ErrConstrainedVertex is returned when removing a vertex that is the
+	// end point of a constraint.
+	ErrConstrainedVertex = errors.New("can not remove a vertex of a constraint")
+	// ErrSegmentTooShort is returned when a segment needs to be split
+	// but it's midpoint is one of it's end points.
+	ErrSegmentTooShort = errors.New("segment too short to split")
+	ErrSeedNotLocated  = errors.New("could not locate seed point")
+	ErrSiteNotInserted = errors.New("failed to insert site")
+	// ErrOutsideHull is returned when a point is outside of the hull of
+	// the points of a subdivision, and the frame would get in the way.
+	ErrOutsideHull = errors.New("point is outside of the hull")
+	// ErrOutsideTIN is returned when a point is outside of the hull of
+	// the points of a TIN.
+	ErrOutsideTIN = errors.New("point is outside of the tin")
+	// ErrMissingZ is returned when a vertex of a TIN has no z value, such
+	// as a point inserted with InsertSite.
+	ErrMissingZ = errors.New("vertex does not have a z value")
+	// ErrPointsNotSorted is returned when a point given to a Streaming
+	// triangulation comes before the last point, by x then y.
+	ErrPointsNotSorted = errors.New("points are not sorted")
+	// ErrOutsideExtent is returned when a point given to a Streaming
+	// triangulation is outside of it's extent.
+	ErrOutsideExtent = errors.New("point is outside of the extent")
 )
 
-type VertexIndex map[geometry.Point]*quadedge.Edge
+// VertexIndex maps the vertices of a subdivision to an edge with that
+// vertex as it's origin. Not all geometry.Point backends are comparable,
+// so the index is keyed by the point's key for the kernel, see
+// geometry.PointKey; points the kernel says are equal are the same vertex.
+//
+// VertexIndex used to be a map[geometry.Point]*quadedge.Edge, and is passed
+// as a *VertexIndex now. Code that used the map should use Get instead of
+// indexing it, Set instead of assigning to it, Each instead of ranging over
+// it and Len instead of len. An index that was built with make is built with
+// NewVertexIndexForKernel(k, nil) instead.
+type VertexIndex struct {
+	kernel geometry.Kernel
+	edges  map[[2]float64]*quadedge.Edge
+}
 
 type Subdivision struct {
 	startingEdge *quadedge.Edge
 	ptcount      int
 	frame        [3]geometry.Point
+	kernel       geometry.Kernel
+	grid         *grid
+	// faceLabels are the labels of the faces, keyed by the edges with
+	// the face on their right. See LabelFaces.
+	faceLabels map[*quadedge.Edge]int
+	// order is the order NewForPoints inserts the points in.
+	order InsertionOrder
+	// pool holds the edges, and the inserted points, of the subdivision;
+	// deleted edges are reused. See EdgeCounts.
+	pool *quadedge.Pool
+}
+
+// Option configures a subdivision.
+type Option func(*Subdivision)
+
+// WithKernel sets the kernel used for the geometric predicates of the
+// subdivision. The default is geometry.BackendKernel.
+func WithKernel(k geometry.Kernel) Option {
+	return func(sd *Subdivision) {
+		if k != nil {
+			sd.kernel = k
+		}
+	}
+}
+
+// WithFaceFunc keeps the data of the faces of the subdivision, given by fn,
+// up to date on the dual edges; e.InvRot().Orig() is then the dual vertex of
+// the face on the left of e, and e.FaceData() it's data. Use Circumcenters
+// for the vertices of the Voronoi diagram.
+func WithFaceFunc(fn quadedge.FaceFunc) Option {
+	return func(sd *Subdivision) {
+		_ = sd.WalkAllEdges(func(e *quadedge.Edge) error {
+			e.SetFaceFunc(fn)
+			return nil
+		})
+	}
 }
 
 // New initialize a subdivision to the triangle defined by the points a,b,c.
-func New(a, b, c geometry.Point) *Subdivision {
-	ea := quadedge.New()
+func New(a, b, c geometry.Point, opts ...Option) *Subdivision {
+	pool := quadedge.NewPool()
+	ea := pool.New()
 	ea.EndPoints(&a, &b)
-	eb := quadedge.New()
+	eb := pool.New()
 	quadedge.Splice(ea.Sym(), eb)
 	eb.EndPoints(&b, &c)
 
-	ec := quadedge.New()
+	ec := pool.New()
 	ec.EndPoints(&c, &a)
 	quadedge.Splice(eb.Sym(), ec)
 	quadedge.Splice(ec.Sym(), ea)
-	return &Subdivision{
+	sd := &Subdivision{
 		startingEdge: ea,
 		ptcount:      3,
 		frame:        [3]geometry.Point{a, b, c},
+		kernel:       geometry.BackendKernel{},
+		pool:         pool,
+	}
+	for _, opt := range opts {
+		opt(sd)
 	}
+	return sd
 }
 
-func NewForPoints(ctx context.Context, points [][2]float64) *Subdivision {
+// EdgeCounts returns the number of edges of the subdivision in use, and the
+// number that have been deleted and are kept to be reused.
+func (sd *Subdivision) EdgeCounts() (live, freed int) {
+	return sd.pool.Len(), sd.pool.Freed()
+}
+
+// NewForPoints returns the Delaunay triangulation of the points, inserted
+// in the order set by WithInsertionOrder; sorted by x then y by default.
+func NewForPoints(ctx context.Context, points [][2]float64, opts ...Option) *Subdivision {
 	sort.Sort(cmp.ByXY(points))
 	tri := geometry.TriangleContaining(points...)
 	ttri := [3]geometry.Point{geometry.NewPoint(tri[0][0], tri[0][1]), geometry.NewPoint(tri[1][0], tri[1][1]), geometry.NewPoint(tri[2][0], tri[2][1])}
-	sd := New(ttri[0], ttri[1], ttri[2])
+	sd := New(ttri[0], ttri[1], ttri[2], opts...)
 	var oldPt geometry.Point
+	sites := make([]geometry.Point, 0, len(points))
 	for i, pt := range points {
-		if ctx.Err() != nil {
-			return nil
-		}
 		bfpt := geometry.NewPoint(pt[0], pt[1])
-		if i != 0 && geometry.ArePointsEqual(oldPt, bfpt) {
+		if i != 0 && sd.kernel.ArePointsEqual(oldPt, bfpt) {
 			continue
 		}
 		oldPt = bfpt
+		sites = append(sites, bfpt)
+	}
+	sd.order.apply(sites)
+	for _, bfpt := range sites {
+		if ctx.Err() != nil {
+			return nil
+		}
 		if !sd.InsertSite(bfpt) {
 			log.Printf("Failed to insert point %v", bfpt)
 		}
@@ -71,55 +168,68 @@ func NewForPoints(ctx context.Context, points [][2]float64) *Subdivision {
 	return sd
 }
 
-func ptEqual(x geometry.Point, a *geometry.Point) bool {
+func ptEqual(k geometry.Kernel, x geometry.Point, a *geometry.Point) bool {
 	if a == nil {
 		return false
 	}
-	return geometry.ArePointsEqual(*a, x)
+	return k.ArePointsEqual(*a, x)
+}
+
+// rightOf indicates if the point is right of the edge, see quadedge.RightOf.
+func rightOf(k geometry.Kernel, x geometry.Point, e *quadedge.Edge) bool {
+	org, dst := e.Orig(), e.Dest()
+	if org == nil || dst == nil {
+		return false
+	}
+	return k.Orientation(x, *dst, *org) > 0
+}
+
+// onEdge determines if the point x is on the edge e, see quadedge.OnEdge.
+func onEdge(k geometry.Kernel, x geometry.Point, e *quadedge.Edge) bool {
+	org, dst := e.Orig(), e.Dest()
+	if org == nil || dst == nil {
+		return false
+	}
+	return k.IsPointOn(geometry.Line{*org, *dst}, x)
 }
 
-func testEdge(x geometry.Point, e *quadedge.Edge) (*quadedge.Edge, bool) {
+func testEdge(k geometry.Kernel, x geometry.Point, e *quadedge.Edge) (*quadedge.Edge, bool) {
 	switch {
-	case ptEqual(x, e.Orig()) || ptEqual(x, e.Dest()):
+	case ptEqual(k, x, e.Orig()) || ptEqual(k, x, e.Dest()):
 		return e, true
-	case quadedge.RightOf(x, e):
+	case rightOf(k, x, e):
 		return e.Sym(), false
-	case !quadedge.RightOf(x, e.ONext()):
+	case !rightOf(k, x, e.ONext()):
 		return e.ONext(), false
-	case !quadedge.RightOf(x, e.DPrev()):
+	case !rightOf(k, x, e.DPrev()):
 		return e.DPrev(), false
 	default:
 		return e, true
 	}
 }
 
-func locate(se *quadedge.Edge, x geometry.Point, limit int) (*quadedge.Edge, bool) {
+func locate(k geometry.Kernel, se *quadedge.Edge, x geometry.Point, limit int) (*quadedge.Edge, bool) {
 	var (
 		e     *quadedge.Edge
 		ok    bool
 		count int
 	)
-	for e, ok = testEdge(x, se); !ok; e, ok = testEdge(x, e) {
+	for e, ok = testEdge(k, x, se); !ok; e, ok = testEdge(k, x, e) {
 		if limit > 0 {
 
 			count++
 			if e == se || count > limit {
-				log.Println("searching all edges for", x)
+				// The walk is lost, fall back to testing every edge.
 				e = nil
 
 				WalkAllEdges(se, func(ee *quadedge.Edge) error {
-					if _, ok = testEdge(x, ee); ok {
+					if _, ok = testEdge(k, x, ee); ok {
 						e = ee
 						return ErrCancel
 					}
 					return nil
 				})
-				log.Printf(
-					"Got back to starting edge after %v iterations, only have %v points ",
-					count,
-					limit,
-				)
-				return e, false
+				return e, e != nil
 			}
 		}
 	}
@@ -127,34 +237,83 @@ func locate(se *quadedge.Edge, x geometry.Point, limit int) (*quadedge.Edge, boo
 
 }
 
-func (sd *Subdivision) VertexIndex() VertexIndex {
-	return NewVertexIndex(sd.startingEdge)
+func (sd *Subdivision) VertexIndex() *VertexIndex {
+	return newVertexIndex(sd.kernel, sd.startingEdge)
 }
 
-// NewVertexIndex will return a new vertex index given a starting edge.
-func NewVertexIndex(startingEdge *quadedge.Edge) VertexIndex {
-	vx := make(VertexIndex)
+// NewVertexIndex will return a new vertex index given a starting edge. The
+// points are keyed for geometry.BackendKernel, use Subdivision.VertexIndex
+// for the kernel of a subdivision.
+func NewVertexIndex(startingEdge *quadedge.Edge) *VertexIndex {
+	return newVertexIndex(geometry.BackendKernel{}, startingEdge)
+}
+
+// NewVertexIndexForKernel will return a new vertex index, keyed for the
+// kernel, of the vertices reachable from the starting edge. If the starting
+// edge is nil the index is empty, edges can then be added with Add or Set.
+// If k is nil geometry.BackendKernel is used.
+func NewVertexIndexForKernel(k geometry.Kernel, startingEdge *quadedge.Edge) *VertexIndex {
+	if k == nil {
+		k = geometry.BackendKernel{}
+	}
+	return newVertexIndex(k, startingEdge)
+}
+
+func newVertexIndex(k geometry.Kernel, startingEdge *quadedge.Edge) *VertexIndex {
+	vx := &VertexIndex{
+		kernel: k,
+		edges:  make(map[[2]float64]*quadedge.Edge),
+	}
 	WalkAllEdges(startingEdge, func(e *quadedge.Edge) error {
 		vx.Add(e)
 		return nil
 	})
 	return vx
 }
-func (vx VertexIndex) Add(e *quadedge.Edge) {
+
+func (vx *VertexIndex) key(pt geometry.Point) [2]float64 {
+	return geometry.PointKey(vx.kernel, pt)
+}
+
+// Get returns the edge with the vertex pt as it's origin.
+func (vx *VertexIndex) Get(pt geometry.Point) (*quadedge.Edge, bool) {
+	e, ok := vx.edges[vx.key(pt)]
+	return e, ok
+}
+
+// Set sets the edge for the vertex that is the origin of e.
+func (vx *VertexIndex) Set(e *quadedge.Edge) {
+	vx.edges[vx.key(*e.Orig())] = e
+}
+
+// Len returns the number of vertices in the index.
+func (vx *VertexIndex) Len() int {
+	return len(vx.edges)
+}
+
+// Each calls fn with the edge of each vertex of the index, in no
+// particular order.
+func (vx *VertexIndex) Each(fn func(e *quadedge.Edge)) {
+	for _, e := range vx.edges {
+		fn(e)
+	}
+}
+
+func (vx *VertexIndex) Add(e *quadedge.Edge) {
 	var (
 		ok   bool
-		orig = *e.Orig()
-		dest = *e.Dest()
+		orig = vx.key(*e.Orig())
+		dest = vx.key(*e.Dest())
 	)
-	if _, ok = vx[orig]; !ok {
-		vx[orig] = e
+	if _, ok = vx.edges[orig]; !ok {
+		vx.edges[orig] = e
 	}
-	if _, ok = vx[dest]; !ok {
-		vx[dest] = e.Sym()
+	if _, ok = vx.edges[dest]; !ok {
+		vx.edges[dest] = e.Sym()
 	}
 }
 
-func (vx VertexIndex) Remove(e *quadedge.Edge) {
+func (vx *VertexIndex) Remove(e *quadedge.Edge) {
 	// Don't think I need e.Rot() and e.Rot().Sym() in this list
 	// as they are face of the quadedge.
 	toRemove := [4]*quadedge.Edge{e, e.Sym(), e.Rot(), e.Rot().Sym()}
@@ -167,16 +326,16 @@ func (vx VertexIndex) Remove(e *quadedge.Edge) {
 		return false
 	}
 
-	for _, v := range [...]geometry.Point{*e.Orig(), *e.Dest()} {
-		ve := vx[v]
+	for _, v := range [...][2]float64{vx.key(*e.Orig()), vx.key(*e.Dest())} {
+		ve := vx.edges[v]
 		if ve == nil || !shouldRemove(ve) {
 			continue
 		}
-		delete(vx, v)
+		delete(vx.edges, v)
 		// See if the ccw edge is the same as us, if it's isn't
 		// then use that as the edge for our lookup.
 		if ve != ve.ONext() {
-			vx[v] = ve.ONext()
+			vx.edges[v] = ve.ONext()
 		}
 	}
 }
@@ -186,14 +345,31 @@ func (vx VertexIndex) Remove(e *quadedge.Edge) {
 // and proceeds in the general direction of x. Based on the
 // pseudocode in Guibas and Stolfi (1985) p.121
 func (sd *Subdivision) locate(x geometry.Point) (*quadedge.Edge, bool) {
-	return locate(sd.startingEdge, x, sd.ptcount*2)
+	se := sd.grid.Edge(x)
+	if se == nil {
+		se = sd.startingEdge
+	}
+	return locate(sd.kernel, se, x, sd.ptcount*2)
+}
+
+// updateGrid rebuilds the grid used to pick a starting edge for locate
+// when the number of points has doubled since it was last built.
+func (sd *Subdivision) updateGrid() {
+	if sd.ptcount < gridMinPoints {
+		return
+	}
+	if sd.grid != nil && sd.ptcount < 2*sd.grid.size {
+		return
+	}
+	sd.grid = newGrid(sd.frame, sd.VertexIndex(), sd.ptcount)
 }
 
-func (sd *Subdivision) FindEdge(vertexIndex VertexIndex, start, end geometry.Point) *quadedge.Edge {
+func (sd *Subdivision) FindEdge(vertexIndex *VertexIndex, start, end geometry.Point) *quadedge.Edge {
 	if vertexIndex == nil {
 		vertexIndex = sd.VertexIndex()
 	}
-	return vertexIndex[start].FindONextDest(end)
+	e, _ := vertexIndex.Get(start)
+	return e.FindONextDest(end)
 }
 
 // InsertSite will insert a new point into a subdivision representing a Delaunay
@@ -201,49 +377,90 @@ func (sd *Subdivision) FindEdge(vertexIndex VertexIndex, start, end geometry.Poi
 // is  still a Delaunay triangulation. This is based on the pseudocode
 // from Guibas and Stolfi (1985) p.120, with slight modificatons and a bug fix.
 func (sd *Subdivision) InsertSite(x geometry.Point) bool {
+	return sd.InsertSiteData(x, nil)
+}
+
+// InsertSiteData inserts the point, like InsertSite, with the data for the
+// vertex; see quadedge.Edge.OrigData. If the point is already in the
+// subdivision it's data is not changed. If the point splits an edge, the
+// two halves get the data of the edge.
+func (sd *Subdivision) InsertSiteData(x geometry.Point, data interface{}) bool {
 	sd.ptcount++
+	sd.updateGrid()
 	e, got := sd.locate(x)
 	if !got {
 		// Did not find the edge using normal walk
 		return false
 	}
+	return sd.insertSiteAt(x, e, data)
+}
 
-	if ptEqual(x, e.Orig()) || ptEqual(x, e.Dest()) {
+// insertSiteAt inserts the point x, with it's data; e is an edge, from
+// locate, with x on it or in the face on it's left.
+func (sd *Subdivision) insertSiteAt(x geometry.Point, e *quadedge.Edge, data interface{}) bool {
+	if ptEqual(sd.kernel, x, e.Orig()) || ptEqual(sd.kernel, x, e.Dest()) {
 		// Point is already in subdivision
 		return true
 	}
 
-	if quadedge.OnEdge(x, e) {
+	// If the point falls on a constraint, the constraint is split in two
+	// at the point.
+	var (
+		splitConstraint bool
+		splitData       interface{}
+		splitEnds       [2]geometry.Point
+	)
+	if onEdge(sd.kernel, x, e) {
 		e = e.OPrev()
 		// Check to see if this point is still alreayd there.
-		if ptEqual(x, e.Orig()) || ptEqual(x, e.Dest()) {
+		if ptEqual(sd.kernel, x, e.Orig()) || ptEqual(sd.kernel, x, e.Dest()) {
 			// Point is already in subdivision
 			return true
 		}
-		quadedge.Delete(e.ONext())
+		split := e.ONext()
+		splitConstraint = split.IsConstrained()
+		splitData = split.Data()
+		splitEnds = [2]geometry.Point{*split.Orig(), *split.Dest()}
+		sd.pool.Delete(split)
 	}
 
 	// Connect the new point to the vertices of the containing
 	// triangle (or quadrilaterial, if the new point fell on an
 	// existing edge.)
-	base := quadedge.NewWithEndPoints(e.Orig(), &x)
+	base := sd.pool.NewWithEndPoints(e.Orig(), sd.pool.Point(x))
+	base.EndPointsData(e.OrigData(), data)
 	quadedge.Splice(base, e)
 	sd.startingEdge = base
+	sd.grid.Add(base.Sym())
 
-	base = quadedge.Connect(e, base.Sym())
+	base = sd.pool.Connect(e, base.Sym())
 	e = base.OPrev()
 	for e.LNext() != sd.startingEdge {
-		base = quadedge.Connect(e, base.Sym())
+		base = sd.pool.Connect(e, base.Sym())
 		e = base.OPrev()
 	}
 
+	if splitConstraint || splitData != nil {
+		spoke := sd.startingEdge.Sym()
+		for {
+			if ptEqual(sd.kernel, splitEnds[0], spoke.Dest()) || ptEqual(sd.kernel, splitEnds[1], spoke.Dest()) {
+				spoke.SetConstrained(splitConstraint)
+				spoke.SetData(splitData)
+			}
+			if spoke = spoke.ONext(); spoke == sd.startingEdge.Sym() {
+				break
+			}
+		}
+	}
+
 	// Examine suspect edges to ensure that the Delaunay condition
-	// is satisfied.
+	// is satisfied. Constraints are never swapped.
 	for {
 		t := e.OPrev()
 		switch {
-		case quadedge.RightOf(*t.Dest(), e) &&
-			geometry.InCircle(*e.Orig(), *t.Dest(), *e.Dest(), x):
+		case !e.IsConstrained() &&
+			rightOf(sd.kernel, *t.Dest(), e) &&
+			geometry.KernelPerturbedInCircle(sd.kernel, *e.Orig(), *t.Dest(), *e.Dest(), x):
 			quadedge.Swap(e)
 			e = e.OPrev()
 
@@ -256,197 +473,22 @@ func (sd *Subdivision) InsertSite(x geometry.Point) bool {
 	return true
 }
 
-func (sd *Subdivision) InsertConstraint(ctx context.Context, vertexIndex VertexIndex, start, end geometry.Point) (err error) {
-
-	if debug {
-
-		ctx = debugger.AugmentContext(ctx, "")
-		defer debugger.Close(ctx)
-
-	}
-	defer func() {
-		if err != nil && err != ErrCoincidentEdges {
-			//DumpSubdivision(sd)
-			fmt.Printf("starting point %#v\n", start)
-			fmt.Printf("end point %#v\n", end)
-		}
-	}()
-
-	var (
-		pu []geometry.Point
-		pl []geometry.Point
-	)
-
-	if vertexIndex == nil {
-		vertexIndex = sd.VertexIndex()
-	}
-
-	startingEdge, ok := vertexIndex[start]
-	if !ok {
-		// start is not in our subdivision
-		return errors.New("Invalid starting vertex.")
-	}
-
-	if e := startingEdge.FindONextDest(end); e != nil {
-		// Nothing to do, edge already in the subdivision.
-		return nil
-	}
-
-	removalList, err := IntersectingEdges(ctx, startingEdge, end)
-	if err != nil && err != ErrCoincidentEdges {
-		return err
-	}
-
-	pu = append(pu, start)
-	pl = append(pl, start)
-
-	for _, e := range removalList {
-		if IsHardFrameEdge(sd.frame, e) {
-			continue
-		}
-		for _, spoint := range [2]geometry.Point{*e.Orig(), *e.Dest()} {
-			switch c := Classify(spoint, start, end); c {
-			case LEFT:
-				pl = geometry.AppendNonRepeat(pl, spoint)
-			case RIGHT:
-				pu = geometry.AppendNonRepeat(pu, spoint)
-			default:
-				/*
-					if debug {
-						log.Printf("Classification: %v -- %v, %v, %v",c,spoint, start,end)
-						// should not come here.
-						return ErrAssumptionFailed()
-					}
-				*/
-				continue
-			}
-		}
-		vertexIndex.Remove(e)
-		quadedge.Delete(e)
-	}
-
-	pl = geometry.AppendNonRepeat(pl, end)
-	pu = geometry.AppendNonRepeat(pu, end)
-
-	for _, pts := range [2][]geometry.Point{pu, pl} {
-		if len(pts) == 2 {
-			// just a shared line, no points to triangulate.
-			continue
-		}
-
-		edges, err := triangulatePseudoPolygon(pts)
-		if err != nil {
-			log.Println("triangulate pseudo polygon fail.")
-			return err
-		}
-
-		var redoedges []int
-		for i, edge := range edges {
-
-			// First we need to check that the edge does not intersect other edges, this can happen if
-			// the polygon we are  triangulating happens to be concave. In which case it is possible
-			// a triangle outside of the "ok" region, and we should ignore those edges
-
-			// Original code think this is a bug: intersectList, _ := intersectingEdges(startingEdge,end)
-			{
-				/*
-					startingEdge := vertexIndex[edge[0]]
-
-					//intersectList, err := IntersectingEdges(ctx, startingEdge, edge[1])
-					intersectList, err := IntersectingEdges(ctx, startingEdge, end)
-					if err != nil && err != ErrCoincidentEdges {
-						log.Println("failed to insert edge check")
-						return err
-					}
-					// filter out intersects only at the end points.
-					count := 0
-					for _, iln := range intersectList {
-						if geometry.ArePointsEqual(*iln.Orig(), edge[0]) ||
-							geometry.ArePointsEqual(*iln.Dest(), edge[0]) ||
-							geometry.ArePointsEqual(*iln.Orig(), edge[1]) ||
-							geometry.ArePointsEqual(*iln.Dest(), edge[1]) {
-							continue
-						}
-						count++
-					}
-					if count > 0 {
-						if debug {
-							debugger.Record(ctx,
-								geometry.UnwrapPoint(edge[0]),
-								"intersecting line:startPoint",
-								"Start Point",
-							)
-							debugger.Record(ctx,
-								geometry.UnwrapPoint(edge[1]),
-								"intersecting line:endPoint",
-								"End Point",
-							)
-							debugger.Record(ctx,
-								startingEdge.AsGeomLine(),
-								"intersecting line:startingedge",
-								"StartingEdge %v", startingEdge.AsGeomLine(),
-							)
-							l := geom.Line{geometry.UnwrapPoint(*startingEdge.Orig()), geometry.UnwrapPoint(edge[1])}
-							debugger.Record(ctx,
-								l,
-								"intersecting line:intersecting",
-								"should not fine any intersects with this line. %v ", l,
-							)
-							for i, il := range intersectList {
-								debugger.Record(ctx,
-									il.AsGeomLine(),
-									"intersecting line:intersected",
-									"line %v of %v -- %v", i, len(intersectList), il.AsGeomLine(),
-								)
-							}
-
-						}
-						log.Println("number of intersectlist found", count)
-						//return errors.New("Should not get here.")
-						continue
-					}
-				*/
-			}
-
-			if err = sd.insertEdge(vertexIndex, edge[0], edge[1]); err != nil {
-				if err == ErrDidNotFindToFrom {
-					// let's reque this edge
-					redoedges = append(redoedges, i)
-					continue
-				}
-				log.Println("Failed to insert edge.")
-				return err
-			}
-		}
-		for _, i := range redoedges {
-			if err = sd.insertEdge(vertexIndex, edges[i][0], edges[i][1]); err != nil {
-				log.Println("Redo Failed to insert edge.", len(redoedges))
-
-				//ignore
-				//	return err
-			}
-		}
-	}
-
-	return nil
-}
-
-func selectCorrectEdges(from, to *quadedge.Edge) (cfrom, cto *quadedge.Edge) {
+func selectCorrectEdges(k geometry.Kernel, from, to *quadedge.Edge) (cfrom, cto *quadedge.Edge) {
 	orig := *from.Orig()
 	dest := *to.Orig()
 	cfrom, cto = from, to
-	log.Printf("curr RightOf(dest)? %v", quadedge.RightOf(dest, cfrom))
-	log.Printf("destedge.Sym RightOf(orig)? %v", quadedge.RightOf(orig, cto))
-	if !quadedge.RightOf(dest, cfrom) {
+	log.Printf("curr RightOf(dest)? %v", rightOf(k, dest, cfrom))
+	log.Printf("destedge.Sym RightOf(orig)? %v", rightOf(k, orig, cto))
+	if !rightOf(k, dest, cfrom) {
 		cfrom = cfrom.OPrev()
 	}
-	if !quadedge.RightOf(orig, cto) {
+	if !rightOf(k, orig, cto) {
 		cto = cto.OPrev()
 	}
 	return cfrom, cto
 }
 
-func resolveEdge(gse *quadedge.Edge, dest geometry.Point) *quadedge.Edge {
+func resolveEdge(k geometry.Kernel, gse *quadedge.Edge, dest geometry.Point) *quadedge.Edge {
 
 	// There aren't any other edges on this vertex.
 	if gse == gse.ONext() {
@@ -457,7 +499,7 @@ func resolveEdge(gse *quadedge.Edge, dest geometry.Point) *quadedge.Edge {
 	se := gse
 	curr := se
 	for {
-		if quadedge.RightOf(dest, curr) {
+		if rightOf(k, dest, curr) {
 			if lre == nil {
 				// reset our starting edge.
 				se = curr
@@ -486,7 +528,7 @@ func resolveEdge(gse *quadedge.Edge, dest geometry.Point) *quadedge.Edge {
 	return se
 }
 
-func findImmediateRightOfEdges(se *quadedge.Edge, dest geometry.Point) (*quadedge.Edge, *quadedge.Edge) {
+func findImmediateRightOfEdges(k geometry.Kernel, se *quadedge.Edge, dest geometry.Point) (*quadedge.Edge, *quadedge.Edge) {
 
 
 
@@ -497,7 +539,7 @@ func findImmediateRightOfEdges(se *quadedge.Edge, dest geometry.Point) (*quadedg
 	curr := se
 	for {
 		log.Printf("top level looking at: %p (%v -> %v)", curr, *curr.Orig(), *curr.Dest())
-		if geometry.ArePointsEqual(*curr.Dest(), dest) {
+		if k.ArePointsEqual(*curr.Dest(), dest) {
 			// edge already in the system.
 			log.Printf("Edge already in system: %p", curr)
 			return curr, nil
@@ -507,11 +549,11 @@ func findImmediateRightOfEdges(se *quadedge.Edge, dest geometry.Point) (*quadedg
 		// Need to see if the dest Next has the dest.
 		for destedge := curr.Sym().ONext(); destedge != curr.Sym(); destedge = destedge.ONext() {
 			log.Printf("\t looking at: %p (%v -> %v)", destedge, *destedge.Orig(), *destedge.Dest())
-			if geometry.ArePointsEqual(*destedge.Dest(), dest) {
+			if k.ArePointsEqual(*destedge.Dest(), dest) {
 				// found what we are looking for.
 				log.Printf("Found the dest! %v -- %p %p", dest, curr, destedge.Sym())
 
-				return selectCorrectEdges(curr, destedge.Sym())
+				return selectCorrectEdges(k, curr, destedge.Sym())
 			}
 			//log.Println("Next:", *destedge.Orig(), *curr.Sym().Orig(), *curr.Sym().Dest())
 
@@ -524,79 +566,6 @@ func findImmediateRightOfEdges(se *quadedge.Edge, dest geometry.Point) (*quadedg
 	return nil, nil
 }
 
-func (sd *Subdivision) insertEdge(vertexIndex VertexIndex, start, end geometry.Point) error {
-	if vertexIndex == nil {
-		vertexIndex = sd.VertexIndex()
-	}
-	startingedge, ok := vertexIndex[start]
-	if !ok {
-		// start is not in our subdivision
-		return errors.New("Invalid starting vertex.")
-	}
-
-	from  := resolveEdge(startingedge,end) 
-	// need to check to see if the dest of from.ONext() is the same as end
-	if geometry.ArePointsEqual(*from.ONext().Dest(), end) {
-		// already in the system.
-		return nil
-	}
-	startingedge, ok = vertexIndex[end]
-	if !ok {
-		// end is not in our subdivision
-		return errors.New("Invalid end vertex.")
-	}
-
-	to := resolveEdge(startingedge, start)
-
-
-
-	/*
-	log.Println("Looking for to and from.")
-	// Now let's find the edge that would be ccw to end
-	from, to := findImmediateRightOfEdges(startingedge.ONext(), end)
-	log.Printf("found for to and from? %p, %p", from, to)
-	if from == nil {
-		// The nodes are too far away or the line we are trying to
-		// insert crosses and already existing line
-		return ErrDidNotFindToFrom
-	}
-	if to == nil {
-		// already in the system
-		return nil
-	}
-
-
-		ct, err := FindIntersectingTriangle(edge, end)
-		if err != nil && err != ErrCoincidentEdges {
-			return err
-		}
-		if ct == nil {
-			return errors.New("did not find an intersecting triangle. assumptions broken.")
-		}
-
-		from := ct.StartingEdge().Sym()
-
-		symEdge, ok := vertexIndex[end]
-		if !ok || symEdge == nil {
-			return errors.New("Invalid ending vertex.")
-		}
-
-		ct, err = FindIntersectingTriangle(symEdge, start)
-		if err != nil && err != ErrCoincidentEdges {
-			return err
-		}
-		if ct == nil {
-			return errors.New("sym did not find an intersecting triangle. assumptions broken.")
-		}
-
-		to := ct.StartingEdge().OPrev()
-	*/
-	newEdge := quadedge.Connect(from, to)
-	log.Printf("Added edge %p", newEdge)
-	vertexIndex.Add(newEdge)
-	return nil
-}
-
 // WalkAllEdges will call the provided function for each edge in the subdivision. The walk will
 // be terminated if the function returns an error or ErrCancel. ErrCancel will not result in
 // an error be returned by main function, otherwise the error will be passed on.
@@ -633,7 +602,7 @@ func (sd *Subdivision) Triangles(includeFrame bool) (triangles [][3]geometry.Poi
 
 			// Do we want to skip because the points are part of the frame and
 			// we have been requested not to include triangles attached to the frame.
-			if IsFramePoint(sd.frame, pts[:]...) && !includeFrame {
+			if sd.isFramePoint(pts[:]...) && !includeFrame {
 				return nil
 			}
 
@@ -644,6 +613,32 @@ func (sd *Subdivision) Triangles(includeFrame bool) (triangles [][3]geometry.Poi
 	return triangles, err
 }
 
+// Vertex is a vertex of the subdivision, and it's data.
+type Vertex struct {
+	geometry.Point
+	Data interface{}
+}
+
+// TrianglesData returns the triangles of the subdivision, like Triangles,
+// with the data of their vertices.
+func (sd *Subdivision) TrianglesData(includeFrame bool) (triangles [][3]Vertex, err error) {
+	err = WalkAllTriangleEdges(sd.startingEdge, func(edges []*quadedge.Edge) error {
+		if len(edges) != 3 {
+			return nil
+		}
+		if !includeFrame && sd.isFramePoint(*edges[0].Orig(), *edges[1].Orig(), *edges[2].Orig()) {
+			return nil
+		}
+		var tri [3]Vertex
+		for i, e := range edges {
+			tri[i] = Vertex{Point: *e.Orig(), Data: e.OrigData()}
+		}
+		triangles = append(triangles, tri)
+		return nil
+	})
+	return triangles, err
+}
+
 func WalkAllEdges(se *quadedge.Edge, fn func(e *quadedge.Edge) error) error {
 	if se == nil {
 		return nil
@@ -679,35 +674,59 @@ func WalkAllEdges(se *quadedge.Edge, fn func(e *quadedge.Edge) error) error {
 
 // IsFrameEdge indicates if the edge is part of the given frame.
 func IsFrameEdge(frame [3]geometry.Point, es ...*quadedge.Edge) bool {
+	return isFrameEdge(geometry.BackendKernel{}, frame, es...)
+}
+
+// IsFrameEdge indicates if the edge is part of the given frame where both vertexs are part of the frame.
+func IsHardFrameEdge(frame [3]geometry.Point, e *quadedge.Edge) bool {
+	return isHardFrameEdge(geometry.BackendKernel{}, frame, e)
+}
+
+func IsFramePoint(frame [3]geometry.Point, pts ...geometry.Point) bool {
+	return isFramePoint(geometry.BackendKernel{}, frame, pts...)
+}
+
+// isFrameEdge is IsFrameEdge, comparing the points with the kernel.
+func isFrameEdge(k geometry.Kernel, frame [3]geometry.Point, es ...*quadedge.Edge) bool {
 	for _, e := range es {
-		o, d := *e.Orig(), *e.Dest()
-		of := geometry.ArePointsEqual(o, frame[0]) || geometry.ArePointsEqual(o, frame[1]) || geometry.ArePointsEqual(o, frame[2])
-		df := geometry.ArePointsEqual(d, frame[0]) || geometry.ArePointsEqual(d, frame[1]) || geometry.ArePointsEqual(d, frame[2])
-		if of || df {
+		if isFramePoint(k, frame, *e.Orig(), *e.Dest()) {
 			return true
 		}
 	}
 	return false
 }
 
-// IsFrameEdge indicates if the edge is part of the given frame where both vertexs are part of the frame.
-func IsHardFrameEdge(frame [3]geometry.Point, e *quadedge.Edge) bool {
-	o, d := *e.Orig(), *e.Dest()
-	of := geometry.ArePointsEqual(o, frame[0]) || geometry.ArePointsEqual(o, frame[1]) || geometry.ArePointsEqual(o, frame[2])
-	df := geometry.ArePointsEqual(d, frame[0]) || geometry.ArePointsEqual(d, frame[1]) || geometry.ArePointsEqual(d, frame[2])
-	return of && df
+// isHardFrameEdge is IsHardFrameEdge, comparing the points with the kernel.
+func isHardFrameEdge(k geometry.Kernel, frame [3]geometry.Point, e *quadedge.Edge) bool {
+	return isFramePoint(k, frame, *e.Orig()) && isFramePoint(k, frame, *e.Dest())
 }
 
-func IsFramePoint(frame [3]geometry.Point, pts ...geometry.Point) bool {
+// isFramePoint is IsFramePoint, comparing the points with the kernel.
+func isFramePoint(k geometry.Kernel, frame [3]geometry.Point, pts ...geometry.Point) bool {
 	for _, pt := range pts {
-		if geometry.ArePointsEqual(pt, frame[0]) ||
-			geometry.ArePointsEqual(pt, frame[1]) ||
-			geometry.ArePointsEqual(pt, frame[2]) {
+		if k.ArePointsEqual(pt, frame[0]) ||
+			k.ArePointsEqual(pt, frame[1]) ||
+			k.ArePointsEqual(pt, frame[2]) {
 			return true
 		}
 	}
 	return false
+}
+
+// isFrameEdge returns if any of the edges has a vertex of the frame.
+func (sd *Subdivision) isFrameEdge(es ...*quadedge.Edge) bool {
+	return isFrameEdge(sd.kernel, sd.frame, es...)
+}
 
+// isHardFrameEdge returns if both vertices of the edge are vertices of
+// the frame.
+func (sd *Subdivision) isHardFrameEdge(e *quadedge.Edge) bool {
+	return isHardFrameEdge(sd.kernel, sd.frame, e)
+}
+
+// isFramePoint returns if any of the points is a vertex of the frame.
+func (sd *Subdivision) isFramePoint(pts ...geometry.Point) bool {
+	return isFramePoint(sd.kernel, sd.frame, pts...)
 }
 
 func constructTriangleEdges(
@@ -770,6 +789,12 @@ func WalkAllTriangleEdges(se *quadedge.Edge, fn func(edges []*quadedge.Edge) err
 }
 
 func FindIntersectingTriangle(startingEdge *quadedge.Edge, end geometry.Point) (*Triangle, error) {
+	return findIntersectingTriangle(geometry.BackendKernel{}, startingEdge, end)
+}
+
+// findIntersectingTriangle is FindIntersectingTriangle using the predicates
+// of the kernel.
+func findIntersectingTriangle(k geometry.Kernel, startingEdge *quadedge.Edge, end geometry.Point) (*Triangle, error) {
 	var (
 		left  = startingEdge
 		right *quadedge.Edge
@@ -778,8 +803,8 @@ func FindIntersectingTriangle(startingEdge *quadedge.Edge, end geometry.Point) (
 	for {
 		right = left.OPrev()
 
-		lc := Classify(end, *left.Orig(), *left.Dest())
-		rc := Classify(end, *right.Orig(), *right.Dest())
+		lc := classify(k, end, *left.Orig(), *left.Dest())
+		rc := classify(k, end, *right.Orig(), *right.Dest())
 
 		if (lc == RIGHT && rc == LEFT) ||
 			lc == BETWEEN ||
@@ -803,6 +828,18 @@ func FindIntersectingTriangle(startingEdge *quadedge.Edge, end geometry.Point) (
 }
 
 func IntersectingEdges(ctx context.Context, startingEdge *quadedge.Edge, end geometry.Point) (intersected []*quadedge.Edge, err error) {
+	return intersectingEdges(ctx, geometry.BackendKernel{}, startingEdge, end)
+}
+
+// IntersectingEdges returns the edges crossed by the line from the origin
+// of the starting edge to end, using the kernel of the subdivision.
+func (sd *Subdivision) IntersectingEdges(ctx context.Context, startingEdge *quadedge.Edge, end geometry.Point) (intersected []*quadedge.Edge, err error) {
+	return intersectingEdges(ctx, sd.kernel, startingEdge, end)
+}
+
+// intersectingEdges is IntersectingEdges using the predicates of the
+// kernel.
+func intersectingEdges(ctx context.Context, k geometry.Kernel, startingEdge *quadedge.Edge, end geometry.Point) (intersected []*quadedge.Edge, err error) {
 
 	if debug {
 
@@ -821,7 +858,7 @@ func IntersectingEdges(ctx context.Context, startingEdge *quadedge.Edge, end geo
 
 	line := geom.Line{geometry.UnwrapPoint(*start), geometry.UnwrapPoint(end)}
 
-	t, err := FindIntersectingTriangle(startingEdge, end)
+	t, err := findIntersectingTriangle(k, startingEdge, end)
 	if err != nil {
 		return nil, err
 	}
@@ -834,8 +871,8 @@ func IntersectingEdges(ctx context.Context, startingEdge *quadedge.Edge, end geo
 		)
 	}
 
-	for !t.IntersectsPoint(end) {
-		if tseq, err = t.OppositeTriangle(*currentPoint); err != nil {
+	for !t.intersectsPoint(k, end) {
+		if tseq, err = t.oppositeTriangle(k, *currentPoint); err != nil {
 			if debug {
 				debugger.Record(ctx,
 					tseq.AsGeom(),
@@ -852,13 +889,13 @@ func IntersectingEdges(ctx context.Context, startingEdge *quadedge.Edge, end geo
 				"Opposite triangle.",
 			)
 		}
-		shared = t.SharedEdge(*tseq)
+		shared = t.sharedEdge(k, *tseq)
 		if shared == nil {
 			// Should I panic? This is weird.
 			return nil, errors.New("did not find shared edge with Opposite Triangle.")
 		}
-		pseq = *tseq.OppositeVertex(*t)
-		switch Classify(pseq, *start, end) {
+		pseq = *tseq.oppositeVertex(k, *t)
+		switch classify(k, pseq, *start, end) {
 		case LEFT:
 			currentPoint = shared.Orig()
 		case RIGHT:
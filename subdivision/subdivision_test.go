@@ -2,10 +2,14 @@ package subdivision
 
 import (
 	"context"
+	"math/rand"
+	"sort"
+	"strconv"
 	"testing"
 
 	"github.com/gdey/quad-edge/debugger"
 	"github.com/gdey/quad-edge/geometry"
+	"github.com/gdey/quad-edge/quadedge"
 	"github.com/go-spatial/geom"
 )
 
@@ -35,7 +39,7 @@ func TestIntersectingEdges(t *testing.T) {
 
 			t.Logf("Finding starting edge")
 			vertexIndex := sd.VertexIndex()
-			startingEdge, ok := vertexIndex[start]
+			startingEdge, ok := vertexIndex.Get(start)
 			if !ok {
 				// start is not in our subdivision
 				t.Error("Invalid starting vertex.")
@@ -37984,3 +37988,292 @@ func TestIntersectingEdges(t *testing.T) {
 		t.Run(name, fn(ctx, tc))
 	}
 }
+
+func TestInsertSiteDegenerate(t *testing.T) {
+	type tcase struct {
+		points [][2]float64
+		// number of triangles not attached to the frame
+		count int
+	}
+
+	// normalize the triangles so that they can be compared, regardless of
+	// the order they were generated in.
+	normalize := func(tris [][3]geometry.Point) [][3]geometry.Point {
+		less := func(a, b geometry.Point) bool {
+			ua, ub := geometry.UnwrapPoint(a), geometry.UnwrapPoint(b)
+			if ua[0] != ub[0] {
+				return ua[0] < ub[0]
+			}
+			return ua[1] < ub[1]
+		}
+		for i := range tris {
+			for !less(tris[i][0], tris[i][1]) || !less(tris[i][0], tris[i][2]) {
+				tris[i][0], tris[i][1], tris[i][2] = tris[i][1], tris[i][2], tris[i][0]
+			}
+		}
+		sort.Slice(tris, func(i, j int) bool {
+			for k := 0; k < 3; k++ {
+				if !geometry.ArePointsEqual(tris[i][k], tris[j][k]) {
+					return less(tris[i][k], tris[j][k])
+				}
+			}
+			return false
+		})
+		return tris
+	}
+
+	triangulate := func(points [][2]float64) *Subdivision {
+		tri := geometry.TriangleContaining(points...)
+		sd := New(
+			geometry.NewPoint(tri[0][0], tri[0][1]),
+			geometry.NewPoint(tri[1][0], tri[1][1]),
+			geometry.NewPoint(tri[2][0], tri[2][1]),
+		)
+		for _, pt := range points {
+			if !sd.InsertSite(geometry.NewPoint(pt[0], pt[1])) {
+				t.Fatalf("failed to insert point %v", pt)
+			}
+		}
+		return sd
+	}
+
+	fn := func(tc tcase) func(*testing.T) {
+		return func(t *testing.T) {
+			expected, err := triangulate(tc.points).Triangles(false)
+			if err != nil {
+				t.Fatalf("error, expected nil got %v", err)
+			}
+			if len(expected) != tc.count {
+				t.Errorf("number of triangles, expected %v got %v", tc.count, len(expected))
+			}
+			for _, tri := range expected {
+				if geometry.Orientation(tri[0], tri[1], tri[2]) == 0 {
+					t.Errorf("degenerate triangle %v", tri)
+				}
+			}
+			expected = normalize(expected)
+
+			// Insertion order should not change the triangulation.
+			rnd := rand.New(rand.NewSource(1))
+			for i := 0; i < 5; i++ {
+				pts := append([][2]float64(nil), tc.points...)
+				rnd.Shuffle(len(pts), func(i, j int) { pts[i], pts[j] = pts[j], pts[i] })
+				got, err := triangulate(pts).Triangles(false)
+				if err != nil {
+					t.Fatalf("error, expected nil got %v", err)
+				}
+				got = normalize(got)
+				if len(got) != len(expected) {
+					t.Errorf("shuffle %v: number of triangles, expected %v got %v", i, len(expected), len(got))
+					continue
+				}
+				for j := range got {
+					if !geometry.ArePointsEqual(got[j][0], expected[j][0]) ||
+						!geometry.ArePointsEqual(got[j][1], expected[j][1]) ||
+						!geometry.ArePointsEqual(got[j][2], expected[j][2]) {
+						t.Errorf("shuffle %v: triangle %v, expected %v got %v", i, j, expected[j], got[j])
+						break
+					}
+				}
+			}
+		}
+	}
+
+	grid := func(n int) (pts [][2]float64) {
+		for x := 0; x < n; x++ {
+			for y := 0; y < n; y++ {
+				pts = append(pts, [2]float64{float64(x * 10), float64(y * 10)})
+			}
+		}
+		return pts
+	}
+
+	tests := []tcase{
+		{points: grid(2), count: 2},
+		{points: grid(3), count: 8},
+		{points: grid(6), count: 50},
+		{ // points on a circle
+			points: [][2]float64{{0, 5}, {3, 4}, {4, 3}, {5, 0}, {4, -3}, {3, -4}, {0, -5}, {-3, -4}, {-4, -3}, {-5, 0}, {-4, 3}, {-3, 4}},
+			count:  10,
+		},
+	}
+	for i, tc := range tests {
+		t.Run(strconv.Itoa(i), fn(tc))
+	}
+}
+
+func TestNewForPointsWithKernel(t *testing.T) {
+	var points [][2]float64
+	for x := 0; x < 6; x++ {
+		for y := 0; y < 6; y++ {
+			points = append(points, [2]float64{float64(x * 10), float64(y * 10)})
+		}
+	}
+	kernels := map[string]geometry.Kernel{
+		"backend":  geometry.BackendKernel{},
+		"float":    geometry.FloatKernel{},
+		"int":      geometry.IntKernel{},
+		"bigfloat": geometry.BigFloatKernel{},
+	}
+	for name, k := range kernels {
+		t.Run(name, func(t *testing.T) {
+			pts := append([][2]float64(nil), points...)
+			sd := NewForPoints(context.Background(), pts, WithKernel(k))
+			tris, err := sd.Triangles(false)
+			if err != nil {
+				t.Fatalf("error, expected nil got %v", err)
+			}
+			if len(tris) != 50 {
+				t.Errorf("number of triangles, expected 50 got %v", len(tris))
+			}
+			for _, tri := range tris {
+				if k.Orientation(tri[0], tri[1], tri[2]) == 0 {
+					t.Errorf("degenerate triangle %v", tri)
+				}
+			}
+
+			// The vertex index finds the points the kernel says are
+			// equal to the vertices.
+			vx := sd.VertexIndex()
+			if vx.Len() != len(points)+3 {
+				t.Errorf("vertex index size, expected %v got %v", len(points)+3, vx.Len())
+			}
+			for _, pt := range points {
+				if _, isInt := k.(geometry.IntKernel); isInt {
+					pt[0], pt[1] = pt[0]+0.3, pt[1]+0.2
+				}
+				if e, ok := vx.Get(geometry.NewPoint(pt[0], pt[1])); !ok || !k.ArePointsEqual(*e.Orig(), geometry.NewPoint(pt[0], pt[1])) {
+					t.Errorf("vertex index %v, expected an edge from it", pt)
+				}
+			}
+
+			// An index built from an empty one is the same as the
+			// subdivision's index.
+			built := NewVertexIndexForKernel(k, nil)
+			if built.Len() != 0 {
+				t.Errorf("empty vertex index size, expected 0 got %v", built.Len())
+			}
+			_ = sd.WalkAllEdges(func(e *quadedge.Edge) error {
+				built.Add(e)
+				return nil
+			})
+			if built.Len() != vx.Len() {
+				t.Errorf("built vertex index size, expected %v got %v", vx.Len(), built.Len())
+			}
+			vx.Each(func(e *quadedge.Edge) {
+				if _, ok := built.Get(*e.Orig()); !ok {
+					t.Errorf("built vertex index %v, expected an edge from it", *e.Orig())
+				}
+			})
+		})
+	}
+}
+
+func TestInsertSiteData(t *testing.T) {
+	ctx := context.Background()
+	// The last two points are the constraint, with a midpoint that can be
+	// represented by all the backends.
+	pts := append(randomPoints(100, 7), [2]float64{2, 2}, [2]float64{1<<20 - 2, 1<<20 - 2})
+	tri := geometry.TriangleContaining(pts...)
+	sd := New(
+		geometry.NewPoint(tri[0][0], tri[0][1]),
+		geometry.NewPoint(tri[1][0], tri[1][1]),
+		geometry.NewPoint(tri[2][0], tri[2][1]),
+	)
+	// The data of a point is it's index.
+	expected := make(map[[2]float64]interface{})
+	for i, pt := range pts {
+		if !sd.InsertSiteData(geometry.NewPoint(pt[0], pt[1]), i) {
+			t.Fatalf("failed to insert point %v", pt)
+		}
+		if _, ok := expected[pt]; !ok {
+			expected[pt] = i
+		}
+	}
+
+	// Constraints swap edges, removing a site connects them.
+	vx := sd.VertexIndex()
+	start, end := geometry.NewPoint(2, 2), geometry.NewPoint(1<<20-2, 1<<20-2)
+	if err := sd.InsertConstraint(ctx, vx, start, end); err != nil {
+		t.Fatalf("insert constraint, expected nil got %v", err)
+	}
+	e := sd.FindEdge(vx, start, end)
+	e.SetData("constraint")
+	if err := sd.RemoveSite(vx, geometry.NewPoint(pts[2][0], pts[2][1])); err != nil {
+		t.Fatalf("remove site, expected nil got %v", err)
+	}
+	delete(expected, pts[2])
+
+	// A point on the constraint splits it, the halves keep the data.
+	mid := geometry.NewPoint(1<<19, 1<<19)
+	if !sd.InsertSiteData(mid, "mid") {
+		t.Fatalf("failed to insert point %v", mid)
+	}
+	expected[geometry.UnwrapPoint(mid)] = "mid"
+	for _, end := range []geometry.Point{start, end} {
+		e := sd.FindEdge(nil, mid, end)
+		if e == nil || !e.IsConstrained() || e.Data() != "constraint" {
+			t.Errorf("half of the constraint to %v, expected constrained edge with data got %v", end, e)
+		}
+	}
+
+	tris, err := sd.TrianglesData(false)
+	if err != nil {
+		t.Fatalf("triangles, expected nil got %v", err)
+	}
+	if len(tris) == 0 {
+		t.Fatalf("triangles, expected some got none")
+	}
+	for _, tri := range tris {
+		for _, v := range tri {
+			if pt := geometry.UnwrapPoint(v.Point); v.Data != expected[pt] {
+				t.Errorf("data of %v, expected %v got %v", pt, expected[pt], v.Data)
+			}
+		}
+	}
+	// All the edges from a vertex have it's data.
+	_ = sd.WalkAllEdges(func(e *quadedge.Edge) error {
+		for _, e := range [2]*quadedge.Edge{e, e.Sym()} {
+			if pt := geometry.UnwrapPoint(*e.Orig()); e.OrigData() != expected[pt] {
+				t.Errorf("data of %v, expected %v got %v", pt, expected[pt], e.OrigData())
+			}
+		}
+		return nil
+	})
+}
+
+func TestEdgeCounts(t *testing.T) {
+	sd := insertPoints(t, [][2]float64{{0, 0}, {10, 0}, {10, 10}, {0, 10}, {5, 4}})
+	count := func() (n int) {
+		_ = sd.WalkAllEdges(func(*quadedge.Edge) error {
+			n++
+			return nil
+		})
+		return n
+	}
+	live, freed := sd.EdgeCounts()
+	if live != count() || freed != 0 {
+		t.Fatalf("edge counts, expected %v 0 got %v %v", count(), live, freed)
+	}
+
+	// Removing a site deletes it's spokes, the hole needs fewer edges.
+	if err := sd.RemoveSite(nil, geometry.NewPoint(5, 4)); err != nil {
+		t.Fatalf("remove site, expected nil got %v", err)
+	}
+	live, freed = sd.EdgeCounts()
+	if live != count() || freed != 3 {
+		t.Errorf("edge counts after remove, expected %v 3 got %v %v", count(), live, freed)
+	}
+
+	// The deleted edges are reused.
+	if !sd.InsertSite(geometry.NewPoint(5, 4)) {
+		t.Fatalf("failed to insert point")
+	}
+	live, freed = sd.EdgeCounts()
+	if live != count() || freed != 0 {
+		t.Errorf("edge counts after insert, expected %v 0 got %v %v", count(), live, freed)
+	}
+	if !sd.IsValid(context.Background()) {
+		t.Errorf("is valid, expected true got false")
+	}
+}
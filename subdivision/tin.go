@@ -0,0 +1,194 @@
+package subdivision
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gdey/quad-edge/geometry"
+	"github.com/gdey/quad-edge/quadedge"
+)
+
+// TIN is a triangulated irregular network; the Delaunay triangulation of
+// points with an elevation, z, for each of them. The z of a vertex is it's
+// data, see InsertSiteData; it is a float64.
+type TIN struct {
+	*Subdivision
+}
+
+// NewTIN returns the TIN of the points. If there are points with the same
+// x and y, the z of the first one is used. The edges of the convex hull of
+// the points are inserted as constraints, otherwise the triangles near the
+// hull can be attached to the frame, and the TIN would not cover all of
+// the hull.
+func NewTIN(ctx context.Context, points [][3]float64, opts ...Option) (*TIN, error) {
+	pts := make([][3]float64, len(points))
+	copy(pts, points)
+	sort.SliceStable(pts, func(i, j int) bool {
+		if pts[i][0] != pts[j][0] {
+			return pts[i][0] < pts[j][0]
+		}
+		return pts[i][1] < pts[j][1]
+	})
+	xy := make([][2]float64, len(pts))
+	for i := range pts {
+		xy[i] = [2]float64{pts[i][0], pts[i][1]}
+	}
+
+	tri := geometry.TriangleContaining(xy...)
+	sd := New(
+		geometry.NewPoint(tri[0][0], tri[0][1]),
+		geometry.NewPoint(tri[1][0], tri[1][1]),
+		geometry.NewPoint(tri[2][0], tri[2][1]),
+		opts...,
+	)
+	for _, pt := range pts {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if !sd.InsertSiteData(geometry.NewPoint(pt[0], pt[1]), pt[2]) {
+			return nil, ErrSiteNotInserted
+		}
+	}
+	if err := sd.constrainHull(ctx, sd.VertexIndex()); err != nil {
+		return nil, err
+	}
+	return &TIN{Subdivision: sd}, nil
+}
+
+// vertexZ returns the z of the origin of e.
+func vertexZ(e *quadedge.Edge) (float64, error) {
+	z, ok := e.OrigData().(float64)
+	if !ok {
+		return 0, ErrMissingZ
+	}
+	return z, nil
+}
+
+// InterpolateZ returns the z at x, y; linearly interpolated from the
+// vertices of the triangle containing the point.
+func (tin *TIN) InterpolateZ(x, y float64) (float64, error) {
+	loc, ok := tin.Locate(geometry.NewPoint(x, y))
+	if !ok || loc.Type == OUTSIDE {
+		return 0, ErrOutsideTIN
+	}
+
+	switch loc.Type {
+	case ON_VERTEX:
+		return vertexZ(loc.Edge)
+
+	case ON_EDGE:
+		// The triangles either side of the edge may be attached to the
+		// frame, so only use the end points.
+		za, err := vertexZ(loc.Edge)
+		if err != nil {
+			return 0, err
+		}
+		zb, err := vertexZ(loc.Edge.Sym())
+		if err != nil {
+			return 0, err
+		}
+		a, b := geometry.UnwrapPoint(*loc.Edge.Orig()), geometry.UnwrapPoint(*loc.Edge.Dest())
+		dx, dy := b[0]-a[0], b[1]-a[1]
+		t := ((x-a[0])*dx + (y-a[1])*dy) / (dx*dx + dy*dy)
+		return za + t*(zb-za), nil
+
+	default: // IN_TRIANGLE
+		edges := [3]*quadedge.Edge{loc.Edge, loc.Edge.LNext(), loc.Edge.LPrev()}
+		var (
+			pts [3][2]float64
+			zs  [3]float64
+		)
+		for i, e := range edges {
+			z, err := vertexZ(e)
+			if err != nil {
+				return 0, err
+			}
+			pts[i], zs[i] = geometry.UnwrapPoint(*e.Orig()), z
+		}
+		// The barycentric coordinates of the point.
+		a, b, c := pts[0], pts[1], pts[2]
+		d := (b[1]-c[1])*(a[0]-c[0]) + (c[0]-b[0])*(a[1]-c[1])
+		wa := ((b[1]-c[1])*(x-c[0]) + (c[0]-b[0])*(y-c[1])) / d
+		wb := ((c[1]-a[1])*(x-c[0]) + (a[0]-c[0])*(y-c[1])) / d
+		return wa*zs[0] + wb*zs[1] + (1-wa-wb)*zs[2], nil
+	}
+}
+
+// TrianglesZ returns the triangles, not attached to the frame, with the z of
+// their vertices. The triangles are counterclockwise.
+func (tin *TIN) TrianglesZ() (triangles [][3][3]float64, err error) {
+	tris, err := tin.TrianglesData(false)
+	if err != nil {
+		return nil, err
+	}
+	for _, tri := range tris {
+		var tz [3][3]float64
+		for i, v := range tri {
+			z, ok := v.Data.(float64)
+			if !ok {
+				return nil, ErrMissingZ
+			}
+			pt := geometry.UnwrapPoint(v.Point)
+			tz[i] = [3]float64{pt[0], pt[1], z}
+		}
+		a, b, c := tz[0], tz[1], tz[2]
+		if (b[0]-a[0])*(c[1]-a[1])-(b[1]-a[1])*(c[0]-a[0]) < 0 {
+			tz[1], tz[2] = tz[2], tz[1]
+		}
+		triangles = append(triangles, tz)
+	}
+	return triangles, nil
+}
+
+// WKTTriangles returns the triangles of the TIN, see TrianglesZ, as
+// WKT TRIANGLE Z geometries.
+func (tin *TIN) WKTTriangles() ([]string, error) {
+	tris, err := tin.TrianglesZ()
+	if err != nil {
+		return nil, err
+	}
+	wkts := make([]string, len(tris))
+	for i, tri := range tris {
+		wkts[i] = "TRIANGLE Z " + wktTriangleZ(tri)
+	}
+	return wkts, nil
+}
+
+// WKTPolyhedralSurface returns the triangles of the TIN, see TrianglesZ, as a
+// WKT POLYHEDRALSURFACE Z geometry.
+func (tin *TIN) WKTPolyhedralSurface() (string, error) {
+	tris, err := tin.TrianglesZ()
+	if err != nil {
+		return "", err
+	}
+	if len(tris) == 0 {
+		return "POLYHEDRALSURFACE Z EMPTY", nil
+	}
+	patches := make([]string, len(tris))
+	for i, tri := range tris {
+		patches[i] = wktTriangleZ(tri)
+	}
+	return "POLYHEDRALSURFACE Z (" + strings.Join(patches, ",") + ")", nil
+}
+
+// wktTriangleZ returns the closed ring of the triangle, as the WKT text of a
+// polygon.
+func wktTriangleZ(tri [3][3]float64) string {
+	var sb strings.Builder
+	sb.WriteString("((")
+	for i := 0; i <= 3; i++ {
+		if i != 0 {
+			sb.WriteByte(',')
+		}
+		for j, v := range tri[i%3] {
+			if j != 0 {
+				sb.WriteByte(' ')
+			}
+			sb.WriteString(strconv.FormatFloat(v, 'f', -1, 64))
+		}
+	}
+	sb.WriteString("))")
+	return sb.String()
+}
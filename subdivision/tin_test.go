@@ -0,0 +1,94 @@
+package subdivision
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/gdey/quad-edge/geometry"
+)
+
+func TestTINInterpolateZ(t *testing.T) {
+	ctx := context.Background()
+	// The points are on a plane, so the interpolated z is on it too.
+	plane := func(x, y float64) float64 { return 2*x - 3*y + 7 }
+	var pts [][3]float64
+	for _, pt := range randomPoints(200, 8) {
+		pts = append(pts, [3]float64{pt[0], pt[1], plane(pt[0], pt[1])})
+	}
+	// Corners, so the hull is the square.
+	for _, pt := range [][2]float64{{0, 0}, {1 << 20, 0}, {1 << 20, 1 << 20}, {0, 1 << 20}} {
+		pts = append(pts, [3]float64{pt[0], pt[1], plane(pt[0], pt[1])})
+	}
+	tin, err := NewTIN(ctx, pts)
+	if err != nil {
+		t.Fatalf("new tin, expected nil got %v", err)
+	}
+
+	rnd := rand.New(rand.NewSource(1))
+	for i := 0; i < 200; i++ {
+		x, y := float64(rnd.Intn(1<<20)), float64(rnd.Intn(1<<20))
+		z, err := tin.InterpolateZ(x, y)
+		if err != nil {
+			t.Errorf("interpolate %v %v, expected nil got %v", x, y, err)
+			continue
+		}
+		if expected := plane(x, y); math.Abs(z-expected) > 1e-6*(1<<20) {
+			t.Errorf("interpolate %v %v, expected %v got %v", x, y, expected, z)
+		}
+	}
+	// On a vertex, and on the hull.
+	for _, pt := range [][2]float64{{pts[0][0], pts[0][1]}, {1 << 19, 0}} {
+		z, err := tin.InterpolateZ(pt[0], pt[1])
+		if err != nil {
+			t.Errorf("interpolate %v, expected nil got %v", pt, err)
+			continue
+		}
+		if expected := plane(pt[0], pt[1]); math.Abs(z-expected) > 1e-6*(1<<20) {
+			t.Errorf("interpolate %v, expected %v got %v", pt, expected, z)
+		}
+	}
+	if _, err := tin.InterpolateZ(-10, -10); err != ErrOutsideTIN {
+		t.Errorf("interpolate outside, expected %v got %v", ErrOutsideTIN, err)
+	}
+}
+
+func TestTINWKT(t *testing.T) {
+	ctx := context.Background()
+	tin, err := NewTIN(ctx, [][3]float64{{0, 0, 1}, {0, 10, 3}, {10, 0, 2.5}})
+	if err != nil {
+		t.Fatalf("new tin, expected nil got %v", err)
+	}
+	tris, err := tin.WKTTriangles()
+	if err != nil {
+		t.Fatalf("wkt triangles, expected nil got %v", err)
+	}
+	// The triangle is counterclockwise, from any vertex.
+	expected := map[string]bool{
+		"TRIANGLE Z ((0 0 1,10 0 2.5,0 10 3,0 0 1))":    true,
+		"TRIANGLE Z ((10 0 2.5,0 10 3,0 0 1,10 0 2.5))": true,
+		"TRIANGLE Z ((0 10 3,0 0 1,10 0 2.5,0 10 3))":   true,
+	}
+	if len(tris) != 1 || !expected[tris[0]] {
+		t.Fatalf("wkt triangles, expected %v got %v", expected, tris)
+	}
+	surface, err := tin.WKTPolyhedralSurface()
+	if err != nil {
+		t.Fatalf("wkt polyhedral surface, expected nil got %v", err)
+	}
+	if expected := "POLYHEDRALSURFACE Z (" + tris[0][len("TRIANGLE Z "):] + ")"; surface != expected {
+		t.Errorf("wkt polyhedral surface, expected %v got %v", expected, surface)
+	}
+
+	// A point without a z.
+	if !tin.InsertSite(geometry.NewPoint(2, 2)) {
+		t.Fatalf("failed to insert point")
+	}
+	if _, err := tin.WKTTriangles(); err != ErrMissingZ {
+		t.Errorf("wkt triangles, expected %v got %v", ErrMissingZ, err)
+	}
+	if _, err := tin.InterpolateZ(2, 3); err != ErrMissingZ {
+		t.Errorf("interpolate, expected %v got %v", ErrMissingZ, err)
+	}
+}
@@ -14,13 +14,18 @@ type Triangle struct {
 func NewTriangle(e *quadedge.Edge) Triangle     { return Triangle{e} }
 func (t Triangle) StartingEdge() *quadedge.Edge { return t.Edge }
 func (t Triangle) IntersectsPoint(pt geometry.Point) bool {
+	return t.intersectsPoint(geometry.BackendKernel{}, pt)
+}
+
+// intersectsPoint is IntersectsPoint using the predicates of the kernel.
+func (t Triangle) intersectsPoint(k geometry.Kernel, pt geometry.Point) bool {
 	e := t.StartingEdge()
 	if e == nil {
 		return false
 	}
 
 	for i := 0; i < 3; i++ {
-		switch Classify(pt, *e.Orig(), *e.Dest()) {
+		switch classify(k, pt, *e.Orig(), *e.Dest()) {
 
 		// return true if v is on the edge
 		case ORIGIN, DESTINATION, BETWEEN:
@@ -48,7 +53,12 @@ func (t Triangle) IntersectsPoint(pt geometry.Point) bool {
 //
 // If this method is called as a.opposedVertex(b), the result will be vertex v2.
 func (t Triangle) OppositeVertex(other Triangle) *geometry.Point {
-	ae := t.SharedEdge(other)
+	return t.oppositeVertex(geometry.BackendKernel{}, other)
+}
+
+// oppositeVertex is OppositeVertex, comparing the points with the kernel.
+func (t Triangle) oppositeVertex(k geometry.Kernel, other Triangle) *geometry.Point {
+	ae := t.sharedEdge(k, other)
 	if ae == nil {
 		return nil
 	}
@@ -68,9 +78,15 @@ func (t Triangle) OppositeVertex(other Triangle) *geometry.Point {
 //
 // If this method is called on triangle a with v1 as the vertex, the result will be triangle b.
 func (t Triangle) OppositeTriangle(p geometry.Point) (*Triangle, error) {
+	return t.oppositeTriangle(geometry.BackendKernel{}, p)
+}
+
+// oppositeTriangle is OppositeTriangle, comparing the points with the
+// kernel.
+func (t Triangle) oppositeTriangle(k geometry.Kernel, p geometry.Point) (*Triangle, error) {
 	start := t.StartingEdge()
 	edge := start
-	for !geometry.ArePointsEqual(*edge.Orig(), p) {
+	for !k.ArePointsEqual(*edge.Orig(), p) {
 		edge = edge.RNext()
 		if edge == start {
 			return nil, errors.New("invalid vertex")
@@ -95,13 +111,18 @@ func (t Triangle) OppositeTriangle(p geometry.Point) (*Triangle, error) {
 // If this method is called as a.sharedEdge(b), the result will be edge lr.
 //
 func (t Triangle) SharedEdge(other Triangle) *quadedge.Edge {
+	return t.sharedEdge(geometry.BackendKernel{}, other)
+}
+
+// sharedEdge is SharedEdge, comparing the points with the kernel.
+func (t Triangle) sharedEdge(k geometry.Kernel, other Triangle) *quadedge.Edge {
 	ae := t.StartingEdge()
 	be := other.StartingEdge()
 
 	for ai := 0; ai < 3; ai, ae = ai+1, ae.RNext() {
 		for bi := 0; bi < 3; bi, be = bi+1, be.RNext() {
-			if geometry.ArePointsEqual(*ae.Orig(), *be.Dest()) &&
-				geometry.ArePointsEqual(*be.Orig(), *ae.Dest()) {
+			if k.ArePointsEqual(*ae.Orig(), *be.Dest()) &&
+				k.ArePointsEqual(*be.Orig(), *ae.Dest()) {
 				return ae
 			}
 		}
@@ -0,0 +1,199 @@
+package subdivision
+
+import (
+	"sort"
+
+	"github.com/gdey/quad-edge/geometry"
+	"github.com/gdey/quad-edge/quadedge"
+	"github.com/go-spatial/geom"
+	"github.com/go-spatial/geom/cmp"
+)
+
+// VoronoiCell is the Voronoi cell of a site of the subdivision.
+type VoronoiCell struct {
+	Site geometry.Point
+	// Polygon is the cell, clipped to the extent it was asked for, with a
+	// single counterclockwise ring.
+	Polygon geom.Polygon
+}
+
+// Voronoi returns the Voronoi cell of each site, not part of the frame, of
+// the subdivision, clipped to the clip extent. Cells entirely outside of the
+// clip extent are left out. The cells are ordered by their sites, by x then y.
+//
+// The Voronoi cell of a site is the dual face of the site; it's vertices are
+// the circumcenters of the triangles around the site, found by walking the
+// face on the left of the Rot() of an edge from the site. The circumcenters of
+// triangles attached to the frame are not vertices of the Voronoi diagram of
+// the sites, the cells of sites next to the frame are unbounded, so these
+// cells are built by clipping the clip extent with the bisectors between the
+// site and it's neighbours instead.
+//
+// The cells are only the Voronoi diagram of the sites if the subdivision is
+// Delaunay; constrained edges that are not Delaunay will give cells that
+// overlap.
+func (sd *Subdivision) Voronoi(clip geom.Extent) (cells []VoronoiCell, err error) {
+	box := [][2]float64{
+		{clip.MinX(), clip.MinY()},
+		{clip.MaxX(), clip.MinY()},
+		{clip.MaxX(), clip.MaxY()},
+		{clip.MinX(), clip.MaxY()},
+	}
+
+	vx := sd.VertexIndex()
+	sites := make([][2]float64, 0, vx.Len())
+	vx.Each(func(e *quadedge.Edge) {
+		if sd.isFramePoint(*e.Orig()) {
+			return
+		}
+		sites = append(sites, geometry.UnwrapPoint(*e.Orig()))
+	})
+	sort.Sort(cmp.ByXY(sites))
+
+	for _, site := range sites {
+		e, _ := vx.Get(geometry.NewPoint(site[0], site[1]))
+		ring, bounded := sd.voronoiRing(e)
+		if bounded {
+			ring = clipToBox(ring, box)
+		} else {
+			ring = box
+			for _, n := range sd.voronoiNeighbours(e) {
+				ring = clipToBisector(ring, site, n)
+			}
+		}
+		if len(ring) < 3 {
+			continue
+		}
+		cells = append(cells, VoronoiCell{
+			Site:    *e.Orig(),
+			Polygon: geom.Polygon{ring},
+		})
+	}
+	return cells, nil
+}
+
+// Circumcenters is a quadedge.FaceFunc that makes the circumcenter of each
+// triangle it's dual vertex. Faces that are not triangles, or are degenerate,
+// don't have a dual vertex.
+func Circumcenters(e *quadedge.Edge) (vertex *geometry.Point, data interface{}) {
+	if e.LNext().LNext().LNext() != e {
+		return nil, nil
+	}
+	pa, pb, pc := e.Orig(), e.Dest(), e.LNext().Dest()
+	if pa == nil || pb == nil || pc == nil {
+		return nil, nil
+	}
+	a, b, c := geometry.UnwrapPoint(*pa), geometry.UnwrapPoint(*pb), geometry.UnwrapPoint(*pc)
+	if (b[0]-a[0])*(c[1]-a[1])-(b[1]-a[1])*(c[0]-a[0]) == 0 {
+		return nil, nil
+	}
+	cc := circumcenter(a, b, c)
+	pt := geometry.NewPoint(cc[0], cc[1])
+	return &pt, nil
+}
+
+// voronoiRing returns the circumcenters of the triangles around the origin of
+// e, counterclockwise; and false if any of the triangles are attached to the
+// frame.
+func (sd *Subdivision) voronoiRing(e *quadedge.Edge) (ring [][2]float64, bounded bool) {
+	// The dual face of the origin of e is on the left of e.Rot(), and
+	// d.LNext() is the Rot() of the next edge around the origin.
+	start := e.Rot()
+	for d := start; ; {
+		s := d.InvRot()
+		pa, pb, pc := *s.Orig(), *s.Dest(), *s.LNext().Dest()
+		if sd.isFramePoint(pa, pb, pc) {
+			return nil, false
+		}
+		c := circumcenter(geometry.UnwrapPoint(pa), geometry.UnwrapPoint(pb), geometry.UnwrapPoint(pc))
+		// Co-circular sites give the same circumcenter more then once.
+		if len(ring) == 0 || ring[len(ring)-1] != c {
+			ring = append(ring, c)
+		}
+		if d = d.LNext(); d == start {
+			break
+		}
+	}
+	if len(ring) > 1 && ring[0] == ring[len(ring)-1] {
+		ring = ring[:len(ring)-1]
+	}
+	if ringArea(ring) < 0 {
+		for i, j := 0, len(ring)-1; i < j; i, j = i+1, j-1 {
+			ring[i], ring[j] = ring[j], ring[i]
+		}
+	}
+	return ring, true
+}
+
+// voronoiNeighbours returns the sites that may share a Voronoi edge with the
+// origin of e. These are the sites it shares an edge with, and, as the frame
+// may hide edges of the hull, the sites that share an edge with a frame
+// point it shares an edge with.
+func (sd *Subdivision) voronoiNeighbours(e *quadedge.Edge) (neighbours [][2]float64) {
+	seen := map[[2]float64]bool{geometry.UnwrapPoint(*e.Orig()): true}
+	// add adds the sites next to the origin of se, and returns the edges
+	// from the frame points next to it.
+	add := func(se *quadedge.Edge) (frame []*quadedge.Edge) {
+		for s := se; ; {
+			if d := *s.Dest(); sd.isFramePoint(d) {
+				frame = append(frame, s.Sym())
+			} else if pt := geometry.UnwrapPoint(d); !seen[pt] {
+				seen[pt] = true
+				neighbours = append(neighbours, pt)
+			}
+			if s = s.ONext(); s == se {
+				return frame
+			}
+		}
+	}
+	for _, fe := range add(e) {
+		add(fe)
+	}
+	return neighbours
+}
+
+// clipToBisector returns the part of the convex polygon that is closer to
+// site then to n.
+func clipToBisector(ring [][2]float64, site, n [2]float64) [][2]float64 {
+	d := [2]float64{n[0] - site[0], n[1] - site[1]}
+	c := (n[0]*n[0] + n[1]*n[1] - site[0]*site[0] - site[1]*site[1]) / 2
+	return clipRing(ring, func(pt [2]float64) float64 {
+		return pt[0]*d[0] + pt[1]*d[1] - c
+	})
+}
+
+// clipToBox returns the part of the convex polygon inside of the box.
+func clipToBox(ring, box [][2]float64) [][2]float64 {
+	min, max := box[0], box[2]
+	ring = clipRing(ring, func(pt [2]float64) float64 { return min[0] - pt[0] })
+	ring = clipRing(ring, func(pt [2]float64) float64 { return pt[0] - max[0] })
+	ring = clipRing(ring, func(pt [2]float64) float64 { return min[1] - pt[1] })
+	return clipRing(ring, func(pt [2]float64) float64 { return pt[1] - max[1] })
+}
+
+// clipRing returns the part of the convex polygon where f is not positive,
+// f needs to be linear. (Sutherland and Hodgman (1974))
+func clipRing(ring [][2]float64, f func([2]float64) float64) (clipped [][2]float64) {
+	for i := range ring {
+		a, b := ring[i], ring[(i+1)%len(ring)]
+		fa, fb := f(a), f(b)
+		if fa <= 0 {
+			clipped = append(clipped, a)
+		}
+		if (fa < 0 && fb > 0) || (fa > 0 && fb < 0) {
+			t := fa / (fa - fb)
+			clipped = append(clipped, [2]float64{a[0] + t*(b[0]-a[0]), a[1] + t*(b[1]-a[1])})
+		}
+	}
+	return clipped
+}
+
+// ringArea returns the signed area of the ring, positive if it is
+// counterclockwise.
+func ringArea(ring [][2]float64) (area float64) {
+	for i := range ring {
+		a, b := ring[i], ring[(i+1)%len(ring)]
+		area += a[0]*b[1] - b[0]*a[1]
+	}
+	return area / 2
+}
@@ -0,0 +1,172 @@
+package subdivision
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"strconv"
+	"testing"
+
+	"github.com/gdey/quad-edge/geometry"
+	"github.com/gdey/quad-edge/quadedge"
+	"github.com/go-spatial/geom"
+)
+
+// inRing returns if the point is inside of, or on, the counterclockwise
+// convex ring.
+func inRing(ring [][2]float64, pt [2]float64) bool {
+	for i := range ring {
+		a, b := ring[i], ring[(i+1)%len(ring)]
+		if (b[0]-a[0])*(pt[1]-a[1])-(b[1]-a[1])*(pt[0]-a[0]) < -1e-6 {
+			return false
+		}
+	}
+	return true
+}
+
+func TestVoronoi(t *testing.T) {
+	type tcase struct {
+		points [][2]float64
+		clip   geom.Extent
+	}
+
+	fn := func(tc tcase) func(*testing.T) {
+		return func(t *testing.T) {
+			sd := insertPoints(t, tc.points)
+			cells, err := sd.Voronoi(tc.clip)
+			if err != nil {
+				t.Fatalf("voronoi, expected nil got %v", err)
+			}
+			if len(cells) != len(tc.points) {
+				t.Fatalf("number of cells, expected %v got %v", len(tc.points), len(cells))
+			}
+
+			// The sites are all inside of the clip, so the cells cover it.
+			var area float64
+			for _, cell := range cells {
+				ring := cell.Polygon[0]
+				a := ringArea(ring)
+				if a <= 0 {
+					t.Errorf("cell %v area, expected positive got %v", cell.Site, a)
+				}
+				area += a
+				if !inRing(ring, geometry.UnwrapPoint(cell.Site)) {
+					t.Errorf("cell %v, expected to contain it's site", cell.Site)
+				}
+			}
+			if expected := (tc.clip.MaxX() - tc.clip.MinX()) * (tc.clip.MaxY() - tc.clip.MinY()); math.Abs(area-expected) > 1e-9*expected {
+				t.Errorf("area, expected %v got %v", expected, area)
+			}
+
+			// Points are in the cell of the closest site.
+			rnd := rand.New(rand.NewSource(1))
+			for i := 0; i < 100; i++ {
+				pt := [2]float64{
+					tc.clip.MinX() + rnd.Float64()*(tc.clip.MaxX()-tc.clip.MinX()),
+					tc.clip.MinY() + rnd.Float64()*(tc.clip.MaxY()-tc.clip.MinY()),
+				}
+				closest, dist := -1, math.Inf(1)
+				for j, cell := range cells {
+					s := geometry.UnwrapPoint(cell.Site)
+					if d := math.Hypot(s[0]-pt[0], s[1]-pt[1]); d < dist {
+						closest, dist = j, d
+					}
+				}
+				if !inRing(cells[closest].Polygon[0], pt) {
+					t.Errorf("point %v, expected in the cell of %v", pt, cells[closest].Site)
+				}
+			}
+		}
+	}
+
+	tests := []tcase{
+		{
+			points: [][2]float64{{10, 10}, {20, 20}, {30, 12}, {15, 30}, {28, 28}},
+			clip:   geom.Extent{0, 0, 40, 40},
+		},
+		// co-circular sites
+		{
+			points: [][2]float64{{10, 10}, {20, 10}, {30, 10}, {10, 20}, {20, 20}, {30, 20}, {10, 30}, {20, 30}, {30, 30}},
+			clip:   geom.Extent{5, 5, 35, 35},
+		},
+		{
+			points: randomPoints(200, 5),
+			clip:   geom.Extent{0, 0, 1 << 20, 1 << 20},
+		},
+	}
+	for i, tc := range tests {
+		t.Run(strconv.Itoa(i), fn(tc))
+	}
+}
+
+func TestVoronoiClipped(t *testing.T) {
+	sd := insertPoints(t, [][2]float64{{10, 10}, {20, 20}, {30, 12}, {15, 30}, {28, 28}})
+	// Only the cell of (10,10) reaches the clip.
+	cells, err := sd.Voronoi(geom.Extent{0, 0, 5, 5})
+	if err != nil {
+		t.Fatalf("voronoi, expected nil got %v", err)
+	}
+	if len(cells) != 1 {
+		t.Fatalf("number of cells, expected 1 got %v", len(cells))
+	}
+	if site := geometry.UnwrapPoint(cells[0].Site); site != [2]float64{10, 10} {
+		t.Errorf("site, expected [10 10] got %v", site)
+	}
+	if area := ringArea(cells[0].Polygon[0]); area != 25 {
+		t.Errorf("area, expected 25 got %v", area)
+	}
+}
+
+func TestWithFaceFunc(t *testing.T) {
+	ctx := context.Background()
+	pts := randomPoints(100, 6)
+	tri := geometry.TriangleContaining(pts...)
+	// The data of a face is the number of edges around it.
+	sides := func(e *quadedge.Edge) (*geometry.Point, interface{}) {
+		v, _ := Circumcenters(e)
+		return v, len(faceEdges(e))
+	}
+	sd := New(
+		geometry.NewPoint(tri[0][0], tri[0][1]),
+		geometry.NewPoint(tri[1][0], tri[1][1]),
+		geometry.NewPoint(tri[2][0], tri[2][1]),
+		WithFaceFunc(sides),
+	)
+	for _, pt := range pts {
+		if !sd.InsertSite(geometry.NewPoint(pt[0], pt[1])) {
+			t.Fatalf("failed to insert point %v", pt)
+		}
+	}
+	// Constraints swap edges, removing a site deletes them.
+	vx := sd.VertexIndex()
+	start, end := geometry.NewPoint(pts[0][0], pts[0][1]), geometry.NewPoint(pts[1][0], pts[1][1])
+	if err := sd.InsertConstraint(ctx, vx, start, end); err != nil {
+		t.Fatalf("insert constraint, expected nil got %v", err)
+	}
+	if err := sd.RemoveSite(vx, geometry.NewPoint(pts[2][0], pts[2][1])); err != nil {
+		t.Fatalf("remove site, expected nil got %v", err)
+	}
+
+	_ = WalkAllTriangleEdges(sd.startingEdge, func(edges []*quadedge.Edge) error {
+		for _, e := range edges {
+			expected, _ := Circumcenters(e)
+			got := e.InvRot().Orig()
+			if (expected == nil) != (got == nil) {
+				t.Errorf("dual vertex of %v, expected %v got %v", e.AsGeomLine(), expected, got)
+				continue
+			}
+			if got == nil {
+				continue
+			}
+			// The vertices may be in a different order.
+			x, g := geometry.UnwrapPoint(*expected), geometry.UnwrapPoint(*got)
+			if math.Hypot(x[0]-g[0], x[1]-g[1]) > 1e-9*math.Hypot(x[0], x[1]) {
+				t.Errorf("dual vertex of %v, expected %v got %v", e.AsGeomLine(), expected, got)
+			}
+			if got := e.FaceData(); got != len(edges) {
+				t.Errorf("face data of %v, expected %v got %v", e.AsGeomLine(), len(edges), got)
+			}
+		}
+		return nil
+	})
+}
@@ -12,6 +12,9 @@ import (
 
 type Triangulator struct {
 	points [][2]float64
+	// Kernel is used for the geometric predicates, if nil
+	// geometry.BackendKernel is used.
+	Kernel geometry.Kernel
 }
 
 func New(pts ...[2]float64) *Triangulator {
@@ -41,13 +44,23 @@ func (t *Triangulator) initSubdivision() {
 */
 
 func (t *Triangulator) Triangles(ctx context.Context, includeFrame bool) (triangles [][3]geometry.Point, err error) {
-	sd := subdivision.NewForPoints(ctx, t.points)
+	sd := subdivision.NewForPoints(ctx, t.points, subdivision.WithKernel(t.Kernel))
 	return sd.Triangles(includeFrame)
 }
 
 type Constrained struct {
 	Points      [][2]float64
 	Constraints [][2][2]float64
+	// Kernel is used for the geometric predicates, if nil
+	// geometry.BackendKernel is used.
+	Kernel geometry.Kernel
+	// SplitIntersecting, if true, splits constraints that cross each
+	// other at the crossing point, otherwise crossing constraints are
+	// an error.
+	SplitIntersecting bool
+	// Steiner are the crossing points added by Triangles when
+	// SplitIntersecting is true.
+	Steiner [][2]float64
 }
 
 func (ct *Constrained) Triangles(ctx context.Context, includeFrame bool) (triangles [][3]geom.Point, err error) {
@@ -55,16 +68,42 @@ func (ct *Constrained) Triangles(ctx context.Context, includeFrame bool) (triang
 	for _, ct := range ct.Constraints {
 		pts = append(pts, ct[0], ct[1])
 	}
-	sd := subdivision.NewForPoints(ctx, pts)
+	sd := subdivision.NewForPoints(ctx, pts, subdivision.WithKernel(ct.Kernel))
 	vxidx := sd.VertexIndex()
-	for _, ct := range ct.Constraints {
-		err = sd.InsertConstraint(ctx, vxidx, geometry.NewPoint(ct[0][0], ct[0][1]), geometry.NewPoint(ct[1][0], ct[1][1]))
+	ct.Steiner = ct.Steiner[:0]
+	for _, c := range ct.Constraints {
+		steiner, err := insertConstraint(ctx, sd, vxidx, c[0], c[1], ct.SplitIntersecting)
+		for _, pt := range steiner {
+			ct.Steiner = append(ct.Steiner, geometry.UnwrapPoint(pt))
+		}
 		if err != nil {
 			return nil, err
 		}
 
 	}
-	return sd.Triangles(includeFrame)
+	tris, err := sd.Triangles(includeFrame)
+	if err != nil {
+		return nil, err
+	}
+	for _, tri := range toGeomTriangles(tris) {
+		triangles = append(triangles, [3]geom.Point{tri[0], tri[1], tri[2]})
+	}
+	return triangles, nil
+}
+
+// toGeomTriangles unwraps the points of the triangles.
+func toGeomTriangles(triangles [][3]geometry.Point) []geom.Triangle {
+	tris := make([]geom.Triangle, 0, len(triangles))
+	for _, tri := range triangles {
+		tris = append(tris,
+			geom.Triangle{
+				geometry.UnwrapPoint(tri[0]),
+				geometry.UnwrapPoint(tri[1]),
+				geometry.UnwrapPoint(tri[2]),
+			},
+		)
+	}
+	return tris
 }
 
 type byLength []geom.Line
@@ -83,9 +122,29 @@ func (l byLength) Less(i, j int) bool {
 	return lilen < ljlen
 }
 
+// insertConstraint inserts the constraint from start to end, splitting
+// crossing constraints if split is true.
+func insertConstraint(ctx context.Context, sd *subdivision.Subdivision, vxidx *subdivision.VertexIndex, start, end [2]float64, split bool) ([]geometry.Point, error) {
+	s, e := geometry.NewPoint(start[0], start[1]), geometry.NewPoint(end[0], end[1])
+	if split {
+		return sd.InsertConstraintSplitting(ctx, vxidx, s, e)
+	}
+	return nil, sd.InsertConstraint(ctx, vxidx, s, e)
+}
+
 type GeomConstrained struct {
 	Points      []geom.Point
 	Constraints []geom.Line
+	// Kernel is used for the geometric predicates, if nil
+	// geometry.BackendKernel is used.
+	Kernel geometry.Kernel
+	// SplitIntersecting, if true, splits constraints that cross each
+	// other at the crossing point, otherwise crossing constraints are
+	// an error.
+	SplitIntersecting bool
+	// Steiner are the crossing points added by Triangles when
+	// SplitIntersecting is true.
+	Steiner []geom.Point
 }
 
 func (ct *GeomConstrained) Triangles(ctx context.Context, includeFrame bool) ([]geom.Triangle, error) {
@@ -96,32 +155,179 @@ func (ct *GeomConstrained) Triangles(ctx context.Context, includeFrame bool) ([]
 	for _, ct := range ct.Constraints {
 		pts = append(pts, ct[0], ct[1])
 	}
-	sd := subdivision.NewForPoints(ctx, pts)
+	sd := subdivision.NewForPoints(ctx, pts, subdivision.WithKernel(ct.Kernel))
 	constraints := ct.Constraints
 	sort.Sort(byLength(constraints))
 
 	vxidx := sd.VertexIndex()
-	for _, ct := range constraints {
-		err := sd.InsertConstraint(ctx, vxidx, geometry.NewPoint(ct[0][0], ct[0][1]), geometry.NewPoint(ct[1][0], ct[1][1]))
+	ct.Steiner = ct.Steiner[:0]
+	for _, c := range constraints {
+		steiner, err := insertConstraint(ctx, sd, vxidx, c[0], c[1], ct.SplitIntersecting)
+		for _, pt := range steiner {
+			ct.Steiner = append(ct.Steiner, geometry.UnwrapPoint(pt))
+		}
 		if err != nil {
 			return nil, err
 		}
 
 	}
-	var tris []geom.Triangle
 	triangles, err := sd.Triangles(includeFrame)
 	if err != nil {
 		return nil, err
 	}
-	for _, tri := range triangles {
-		tris = append(tris,
-			geom.Triangle{
-				geometry.UnwrapPoint(tri[0]),
-				geometry.UnwrapPoint(tri[1]),
-				geometry.UnwrapPoint(tri[2]),
-			},
-		)
+	return toGeomTriangles(triangles), nil
+
+}
+
+// Conforming is a conforming Delaunay triangulation. The constraints are
+// split, by inserting their midpoints, until each part is an edge of the
+// Delaunay triangulation; unlike Constrained the triangulation is Delaunay
+// everywhere.
+type Conforming struct {
+	Points      []geom.Point
+	Constraints []geom.Line
+	// Kernel is used for the geometric predicates, if nil
+	// geometry.BackendKernel is used.
+	Kernel geometry.Kernel
+	// Steiner are the midpoints added by Triangles.
+	Steiner []geom.Point
+}
+
+func (ct *Conforming) Triangles(ctx context.Context, includeFrame bool) ([]geom.Triangle, error) {
+	var pts [][2]float64
+	for _, pt := range ct.Points {
+		pts = append(pts, [2]float64(pt))
 	}
-	return tris, nil
+	segments := make([][2]geometry.Point, 0, len(ct.Constraints))
+	for _, c := range ct.Constraints {
+		pts = append(pts, c[0], c[1])
+		segments = append(segments, [2]geometry.Point{
+			geometry.NewPoint(c[0][0], c[0][1]),
+			geometry.NewPoint(c[1][0], c[1][1]),
+		})
+	}
+	sd := subdivision.NewForPoints(ctx, pts, subdivision.WithKernel(ct.Kernel))
+
+	ct.Steiner = ct.Steiner[:0]
+	steiner, err := sd.InsertConforming(ctx, sd.VertexIndex(), segments...)
+	for _, pt := range steiner {
+		ct.Steiner = append(ct.Steiner, geometry.UnwrapPoint(pt))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	triangles, err := sd.Triangles(includeFrame)
+	if err != nil {
+		return nil, err
+	}
+	return toGeomTriangles(triangles), nil
+}
+
+// RefineOptions are the points and constraints to triangulate, and the
+// limits on the triangles of the refined triangulation.
+type RefineOptions struct {
+	Points      []geom.Point
+	Constraints []geom.Line
+	// Kernel is used for the geometric predicates, if nil
+	// geometry.BackendKernel is used.
+	Kernel geometry.Kernel
+	subdivision.RefineOptions
+}
+
+// Refine builds the constrained Delaunay triangulation of the points and
+// constraints, and inserts points until it's triangles are within the limits
+// of opts, see subdivision.Subdivision.Refine. The triangles of the refined
+// triangulation, and the points that were inserted, are returned.
+func Refine(ctx context.Context, opts RefineOptions) (tris []geom.Triangle, steiner []geom.Point, err error) {
+	var pts [][2]float64
+	for _, pt := range opts.Points {
+		pts = append(pts, [2]float64(pt))
+	}
+	for _, c := range opts.Constraints {
+		pts = append(pts, c[0], c[1])
+	}
+	sd := subdivision.NewForPoints(ctx, pts, subdivision.WithKernel(opts.Kernel))
+	vxidx := sd.VertexIndex()
+	for _, c := range opts.Constraints {
+		if _, err = insertConstraint(ctx, sd, vxidx, c[0], c[1], false); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	points, err := sd.Refine(ctx, vxidx, opts.RefineOptions)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, pt := range points {
+		steiner = append(steiner, geometry.UnwrapPoint(pt))
+	}
+
+	triangles, err := sd.Triangles(false)
+	if err != nil {
+		return nil, nil, err
+	}
+	return toGeomTriangles(triangles), steiner, nil
+}
+
+// TriangulatePolygon returns the triangles of the constrained Delaunay
+// triangulation of the polygon that are inside of it's shell, and outside of
+// it's holes. The rings of the polygon are the constraints.
+func TriangulatePolygon(ctx context.Context, polygon geom.Polygon) ([]geom.Triangle, error) {
+	return PolygonTriangulator{}.Triangulate(ctx, polygon)
+}
+
+// TriangulateMultiPolygon returns the triangles of each of the polygons,
+// see TriangulatePolygon.
+func TriangulateMultiPolygon(ctx context.Context, multiPolygon geom.MultiPolygon) ([]geom.Triangle, error) {
+	return PolygonTriangulator{}.TriangulateMulti(ctx, multiPolygon)
+}
+
+// PolygonTriangulator triangulates polygons, like TriangulatePolygon, with
+// the given kernel.
+type PolygonTriangulator struct {
+	// Kernel is used for the geometric predicates, if nil
+	// geometry.BackendKernel is used.
+	Kernel geometry.Kernel
+}
+
+// Triangulate returns the triangles of the polygon, see TriangulatePolygon.
+func (pt PolygonTriangulator) Triangulate(ctx context.Context, polygon geom.Polygon) ([]geom.Triangle, error) {
+	var pts [][2]float64
+	for _, ring := range polygon {
+		pts = append(pts, ring...)
+	}
+	if len(pts) < 3 {
+		return nil, nil
+	}
+	sd := subdivision.NewForPoints(ctx, pts, subdivision.WithKernel(pt.Kernel))
+	vxidx := sd.VertexIndex()
+	for _, ring := range polygon {
+		for i := range ring {
+			// The ring may or may not be closed.
+			a, b := ring[i], ring[(i+1)%len(ring)]
+			if _, err := insertConstraint(ctx, sd, vxidx, a, b, false); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	triangles, err := sd.InsideTriangles()
+	if err != nil {
+		return nil, err
+	}
+	return toGeomTriangles(triangles), nil
+}
 
+// TriangulateMulti returns the triangles of each of the polygons, see
+// TriangulatePolygon.
+func (pt PolygonTriangulator) TriangulateMulti(ctx context.Context, multiPolygon geom.MultiPolygon) (tris []geom.Triangle, err error) {
+	for _, polygon := range multiPolygon {
+		ptris, err := pt.Triangulate(ctx, polygon)
+		if err != nil {
+			return nil, err
+		}
+		tris = append(tris, ptris...)
+	}
+	return tris, nil
 }